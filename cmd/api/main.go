@@ -1,21 +1,32 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"log/slog"
 
 	"backend.go.characters.api/internal/adapters/logger"
-	"backend.go.characters.api/internal/adapters/primary/http"
+	"backend.go.characters.api/internal/adapters/metrics"
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
 	"backend.go.characters.api/internal/adapters/secondary/db/postgres"
 	"backend.go.characters.api/internal/adapters/secondary/dragonballapi"
+	"backend.go.characters.api/internal/adapters/secondary/lrucache"
+	"backend.go.characters.api/internal/adapters/secondary/rediscache"
+	"backend.go.characters.api/internal/adapters/tracing"
+	"backend.go.characters.api/internal/core/domain"
+	"backend.go.characters.api/internal/core/ports"
 	"backend.go.characters.api/internal/core/services"
 	"backend.go.characters.api/internal/infrastructure/config"
-	"github.com/gin-gonic/gin"
+	"backend.go.characters.api/migrations"
 	_ "github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -27,18 +38,50 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Replace the bootstrap logger with the configured one
+	logLevel, err := parseLogLevel(cfg.LogLevel)
+	if err != nil {
+		appLogger.Error("Invalid log level", slog.String("error", err.Error()))
+		log.Fatalf("Invalid log level: %v", err)
+	}
+	appLogger, logOutput, err := logger.New(logger.Options{
+		Level:     logLevel,
+		Format:    cfg.LogFormat,
+		AddSource: cfg.LogAddSource,
+		File: logger.FileOptions{
+			Path:       cfg.LogFile,
+			MaxSizeMB:  cfg.LogFileMaxSizeMB,
+			MaxBackups: cfg.LogFileMaxBackups,
+		},
+		Sampling: logger.SamplingOptions{
+			Initial:    cfg.LogSamplingInitial,
+			Thereafter: cfg.LogSamplingThereafter,
+			Tick:       cfg.LogSamplingTick,
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure logger: %v", err)
+	}
+	defer logOutput.Close()
+
+	// Spans are exported as configured, W3C trace context is always propagated
+	tracerProvider, err := tracing.NewTracerProvider(context.Background(), tracing.Options{
+		Exporter:    cfg.TracingExporter,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		appLogger.Error("Failed to configure tracing", slog.String("error", err.Error()))
+		log.Fatalf("Failed to configure tracing: %v", err)
+	}
+	tracing.Install(tracerProvider)
+
 	// Initialize database connection
 	db, err := sql.Open("postgres", cfg.DatabaseURL)
 	if err != nil {
 		appLogger.Error("Failed to connect to database", slog.String("error", err.Error()))
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			appLogger.Error("Failed to close database connection", slog.String("error", err.Error()))
-		}
-	}()
-
 	// Ping database to ensure connection is established
 	for i := 0; i < 5; i++ { // Retry connection
 		err = db.Ping()
@@ -54,51 +97,184 @@ func main() {
 		log.Fatalf("Could not connect to database: %v", err)
 	}
 
-	// Apply migrations (simple for demo, consider using a proper migration tool)
-	if err := applyMigrations(db, appLogger); err != nil {
-		appLogger.Error("Failed to apply database migrations", slog.String("error", err.Error()))
-		log.Fatalf("Failed to apply database migrations: %v", err)
+	// Collectors of every adapter are exposed on /metrics
+	metricsRegistry := metrics.NewRegistry()
+
+	postgresRepository := postgres.NewCharacterRepository(db, appLogger, metricsRegistry)
+
+	// Apply pending migrations, replicas serialise on an advisory lock
+	migrator, err := postgres.NewMigrator(db, migrations.FS, appLogger)
+	if err != nil {
+		appLogger.Error("Failed to load database migrations", slog.String("error", err.Error()))
+		log.Fatalf("Failed to load database migrations: %v", err)
+	}
+	if cfg.MigrateOnStartup {
+		if err := migrator.Up(context.Background()); err != nil {
+			appLogger.Error("Failed to apply database migrations", slog.String("error", err.Error()))
+			log.Fatalf("Failed to apply database migrations: %v", err)
+		}
+		appLogger.Info("Database migrations applied", slog.Int64("version", migrator.LatestVersion()))
+		// Rows stored before the ki sort key existed would sort and filter as unknown
+		if _, err := postgresRepository.BackfillKiSortKeys(context.Background()); err != nil {
+			appLogger.Error("Failed to backfill ki sort keys", slog.String("error", err.Error()))
+			log.Fatalf("Failed to backfill ki sort keys: %v", err)
+		}
 	}
 
 	// Initialize adapters
-	characterRepository := postgres.NewCharacterRepository(db, appLogger)
-	dragonBallAPIClient := dragonballapi.NewDragonBallAPIClient(appLogger)
+	var characterRepository ports.CharacterRepository = postgresRepository
+	if cfg.RepositoryCacheSize > 0 {
+		// Hot characters are served from memory instead of Postgres
+		characterRepository = lrucache.NewCharacterRepository(characterRepository, appLogger, lrucache.Options{
+			MaxEntries: cfg.RepositoryCacheSize,
+			MaxBytes:   int64(cfg.RepositoryCacheMaxBytes),
+			TTL:        cfg.RepositoryCacheTTL,
+			Registerer: metricsRegistry,
+		})
+	}
+	dragonBallAPIClient, err := dragonballapi.NewDragonBallAPIClient(appLogger, dragonballapi.Options{
+		BaseURL:        cfg.DragonBallAPIBaseURL,
+		RequestTimeout: cfg.DragonBallAPIRequestTimeout,
+		UserAgent:      cfg.DragonBallAPIUserAgent,
+		MaxIdleConns:   cfg.DragonBallAPIMaxIdleConns,
+		TLS: dragonballapi.TLSOptions{
+			CAFile:             cfg.DragonBallAPITLSCAFile,
+			InsecureSkipVerify: cfg.DragonBallAPITLSInsecureSkipVerify,
+		},
+		RetryPolicy: dragonballapi.RetryPolicy{
+			MaxRetries: cfg.DragonBallAPIMaxRetries,
+			BaseDelay:  cfg.DragonBallAPIRetryBaseDelay,
+			MaxDelay:   cfg.DragonBallAPIRetryMaxDelay,
+		},
+		CircuitBreaker: dragonballapi.CircuitBreakerSettings{
+			FailureThreshold: cfg.DragonBallAPIBreakerThreshold,
+			OpenTimeout:      cfg.DragonBallAPIBreakerOpenTimeout,
+		},
+		Registerer: metricsRegistry,
+	})
+	if err != nil {
+		appLogger.Error("Failed to configure Dragon Ball API client", slog.String("error", err.Error()))
+		log.Fatalf("Failed to configure Dragon Ball API client: %v", err)
+	}
+
+	// Replicas share the characters they looked up through Redis, when configured
+	var characterCache ports.CharacterCache
+	var redisClient *redis.Client
+	if cfg.RedisURL != "" {
+		redisOptions, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			appLogger.Error("Invalid Redis URL", slog.String("error", err.Error()))
+			log.Fatalf("Invalid Redis URL: %v", err)
+		}
+		redisClient = redis.NewClient(redisOptions)
+		characterCache = rediscache.NewCharacterCache(redisClient, appLogger, rediscache.Options{
+			TTL:       cfg.RedisCacheTTL,
+			KeyPrefix: cfg.RedisKeyPrefix,
+		})
+	}
 
 	// Initialize core service
-	characterService := services.NewCharacterService(characterRepository, dragonBallAPIClient, appLogger)
+	freshness := domain.FreshnessPolicy{
+		TTL:         cfg.CharacterFreshTTL,
+		MaxAge:      cfg.CharacterMaxAge,
+		NotFoundTTL: cfg.CharacterNotFoundTTL,
+	}
+	characterService := services.NewCharacterService(characterRepository, dragonBallAPIClient, characterCache, postgresRepository, metrics.NewCharacterMetrics(metricsRegistry), freshness, appLogger)
+
+	// Initialize HTTP handlers
+	planetService := services.NewPlanetService(postgresRepository, characterRepository, characterCache, dragonBallAPIClient, freshness, appLogger)
+	characterHandler := httpadapter.NewCharacterHandler(characterService, appLogger)
+	planetHandler := httpadapter.NewPlanetHandler(planetService, appLogger)
+	logLevelHandler := httpadapter.NewLogLevelHandler(logLevel, appLogger)
+	unknownNamesHandler := httpadapter.NewUnknownNamesHandler(characterService, appLogger)
+	dependencyChecks := []httpadapter.DependencyCheck{
+		{Checker: postgres.NewDatabaseHealthChecker(db), Critical: true},
+		{Checker: migrator, Critical: true},
+		// Cached characters are still served while the upstream is down
+		{Checker: dragonBallAPIClient, Critical: false},
+	}
+	if redisClient != nil {
+		// Lookups fall back to the database while Redis is down
+		dependencyChecks = append(dependencyChecks, httpadapter.DependencyCheck{Checker: rediscache.NewRedisHealthChecker(redisClient), Critical: false})
+	}
+	healthHandler := httpadapter.NewHealthHandler(appLogger, dependencyChecks...)
+
+	router := httpadapter.NewRouter(httpadapter.RouterConfig{
+		Logger:              appLogger,
+		MetricsRegistry:     metricsRegistry,
+		CharacterHandler:    characterHandler,
+		PlanetHandler:       planetHandler,
+		HealthHandler:       healthHandler,
+		LogLevelHandler:     logLevelHandler,
+		UnknownNamesHandler: unknownNamesHandler,
+		AdminToken:          cfg.AdminToken,
+		AccessLogSkipPaths:  cfg.AccessLogSkipPaths,
+	})
 
-	// Initialize HTTP handler
-	characterHandler := http.NewCharacterHandler(characterService, appLogger)
+	server := &http.Server{
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
 
-	// Set up Gin router
-	router := gin.Default()
-	router.POST("/characters", characterHandler.CreateCharacter)
+	// Stop on SIGINT (Ctrl+C) and SIGTERM (docker stop, Kubernetes)
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		appLogger.Info(fmt.Sprintf("Starting server on :%s", cfg.Port))
+		serverErr <- server.ListenAndServe()
+	}()
 
-	appLogger.Info(fmt.Sprintf("Starting server on :%s", cfg.Port))
-	if err := router.Run(":" + cfg.Port); err != nil {
+	select {
+	case err := <-serverErr:
 		appLogger.Error("Failed to start server", slog.String("error", err.Error()))
 		log.Fatalf("Failed to run server: %v", err)
+	case <-ctx.Done():
+		stop() // A second signal kills the process right away
+	}
+
+	// Fail readiness first, give load balancers time to notice, then drain
+	appLogger.Info("Shutdown signal received, draining in-flight requests", slog.Duration("timeout", cfg.ShutdownTimeout))
+	healthHandler.SetShuttingDown()
+	time.Sleep(cfg.ShutdownDelay)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to drain in-flight requests before the timeout", slog.String("error", err.Error()))
+	}
+	// Background refreshes started by the drained requests still use the client and the pools
+	if err := characterService.Close(shutdownCtx); err != nil {
+		appLogger.Error("Failed to finish background character refreshes before the timeout", slog.String("error", err.Error()))
+	}
+	if err := planetService.Close(shutdownCtx); err != nil {
+		appLogger.Error("Failed to finish background planet imports before the timeout", slog.String("error", err.Error()))
+	}
+
+	dragonBallAPIClient.Close()
+	if redisClient != nil {
+		if err := redisClient.Close(); err != nil {
+			appLogger.Error("Failed to close Redis connection", slog.String("error", err.Error()))
+		}
+	}
+	if err := db.Close(); err != nil {
+		appLogger.Error("Failed to close database connection", slog.String("error", err.Error()))
+	}
+	// Flush the spans of the drained requests
+	if err := tracerProvider.Shutdown(shutdownCtx); err != nil {
+		appLogger.Error("Failed to flush pending spans", slog.String("error", err.Error()))
 	}
+	appLogger.Info("Server stopped")
 }
 
-// applyMigrations is a simple function to apply schema.
-// For production, consider using a dedicated migration library like golang-migrate/migrate.
-func applyMigrations(db *sql.DB, logger *slog.Logger) error {
-	createTableSQL := `
-	CREATE TABLE IF NOT EXISTS characters (
-		id VARCHAR(255) PRIMARY KEY,
-		name VARCHAR(255) NOT NULL,
-		ki VARCHAR(255),
-		race VARCHAR(255),
-		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-	);
-	`
-	_, err := db.Exec(createTableSQL)
+func parseLogLevel(name string) (*slog.LevelVar, error) {
+	level, err := logger.ParseLevel(name)
 	if err != nil {
-		logger.Error("Error creating characters table", slog.String("error", err.Error()))
-		return fmt.Errorf("error creating characters table: %w", err)
+		return nil, err
 	}
-	logger.Info("Characters table ensured to exist or created.")
-	return nil
+	levelVar := new(slog.LevelVar)
+	levelVar.Set(level)
+	return levelVar, nil
 }
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/adapters/logger"
+	"backend.go.characters.api/internal/adapters/secondary/db/postgres"
+	"backend.go.characters.api/internal/infrastructure/config"
+	"backend.go.characters.api/migrations"
+	_ "github.com/lib/pq"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up              apply every pending migration and backfill the data they need
+  down [N]        roll back the last N applied migrations (default 1)
+  goto VERSION    migrate up or down to VERSION (0 rolls back everything), then
+                  backfill the data the migrations up to VERSION need
+  status          list migrations and whether they are applied`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	appLogger := logger.NewSlogLogger()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		appLogger.Error("Failed to load configuration", slog.String("error", err.Error()))
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := sql.Open("postgres", cfg.DatabaseURL)
+	if err != nil {
+		appLogger.Error("Failed to connect to database", slog.String("error", err.Error()))
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := postgres.NewMigrator(db, migrations.FS, appLogger)
+	if err != nil {
+		appLogger.Error("Failed to load database migrations", slog.String("error", err.Error()))
+		log.Fatalf("Failed to load database migrations: %v", err)
+	}
+
+	// Metrics are not exposed by this command
+	characterRepository := postgres.NewCharacterRepository(db, appLogger, nil)
+
+	if err := run(context.Background(), migrator, characterRepository, os.Args[1:]); err != nil {
+		appLogger.Error("Migration command failed", slog.String("command", os.Args[1]), slog.String("error", err.Error()))
+		log.Fatalf("Migration command failed: %v", err)
+	}
+}
+
+// kiBackfiller sets the ki sort key of the characters stored before it existed.
+type kiBackfiller interface {
+	BackfillKiSortKeys(ctx context.Context) (int64, error)
+}
+
+func run(ctx context.Context, migrator *postgres.Migrator, characters kiBackfiller, args []string) error {
+	switch args[0] {
+	case "up":
+		if err := migrator.Up(ctx); err != nil {
+			return err
+		}
+		_, err := characters.BackfillKiSortKeys(ctx)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("down expects a positive number of steps, got %q", args[1])
+			}
+			steps = n
+		}
+		return migrator.Down(ctx, steps)
+	case "goto":
+		if len(args) < 2 {
+			return fmt.Errorf("goto expects a version")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("goto expects a non-negative version, got %q", args[1])
+		}
+		if err := migrator.Goto(ctx, version); err != nil {
+			return err
+		}
+		// Like up, a schema holding the ki sort key gets it for the older rows
+		if version < postgres.KiSortKeyVersion {
+			return nil
+		}
+		_, err = characters.BackfillKiSortKeys(ctx)
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05Z07:00")
+			}
+			if status.Modified {
+				state += " (modified since applied)"
+			}
+			fmt.Printf("%06d  %-40s  %s\n", status.Version, status.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	defaultMaxSizeMB  = 100
+	defaultMaxBackups = 5
+)
+
+// rotatingFile is a log file that is rotated once it reaches its maximum
+// size: app.log becomes app.log.1, app.log.1 becomes app.log.2 and so on,
+// keeping at most maxBackups old files.
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+func newRotatingFile(opts FileOptions) (*rotatingFile, error) {
+	if opts.MaxSizeMB <= 0 {
+		opts.MaxSizeMB = defaultMaxSizeMB
+	}
+	if opts.MaxBackups <= 0 {
+		opts.MaxBackups = defaultMaxBackups
+	}
+	f := &rotatingFile{path: opts.Path, maxSize: int64(opts.MaxSizeMB) << 20, maxBackups: opts.MaxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+	// A failed rotation keeps appending to the current file, so the sink
+	// goes on logging and the rotation is tried again on the next write.
+	var rotateErr error
+	if f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		if rotateErr = f.rotate(); f.file == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// rotate shifts the backups and moves the current file to the first one. The
+// log file is reopened whatever fails on the way, so that a failed rotation
+// leaves the current file open for appending instead of a closed one.
+func (f *rotatingFile) rotate() error {
+	var errs []error
+	if err := f.file.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close log file: %w", err))
+	}
+	f.file = nil
+	if err := os.Remove(backupName(f.path, f.maxBackups)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		errs = append(errs, fmt.Errorf("failed to remove oldest log backup: %w", err))
+	}
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(backupName(f.path, i), backupName(f.path, i+1)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("failed to shift log backup: %w", err))
+		}
+	}
+	if err := os.Rename(f.path, backupName(f.path, 1)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to rotate log file: %w", err))
+	}
+	if err := f.open(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to reopen log file: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func backupName(path string, index int) string {
+	return fmt.Sprintf("%s.%d", path, index)
+}
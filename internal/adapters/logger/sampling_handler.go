@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const defaultSamplingTick = time.Second
+
+// samplingHandler drops repetitive records below Warn, see SamplingOptions.
+// Handlers derived through WithAttrs and WithGroup share the same counters.
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler
+}
+
+func newSamplingHandler(handler slog.Handler, opts SamplingOptions) *samplingHandler {
+	if opts.Tick <= 0 {
+		opts.Tick = defaultSamplingTick
+	}
+	return &samplingHandler{Handler: handler, sampler: &sampler{opts: opts, counts: map[string]*sampleCount{}}}
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < slog.LevelWarn && !h.sampler.allow(record.Message, record.Time) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler}
+}
+
+type sampleCount struct {
+	start time.Time
+	n     int
+}
+
+type sampler struct {
+	opts   SamplingOptions
+	mu     sync.Mutex
+	counts map[string]*sampleCount
+}
+
+// allow counts a record with message and reports whether it is kept. Log
+// messages are constant strings, so the counters stay bounded.
+func (s *sampler) allow(message string, at time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count, ok := s.counts[message]
+	if !ok || at.Sub(count.start) >= s.opts.Tick {
+		count = &sampleCount{start: at}
+		s.counts[message] = count
+	}
+	count.n++
+	if count.n <= s.opts.Initial {
+		return true
+	}
+	return s.opts.Thereafter > 0 && (count.n-s.opts.Initial)%s.opts.Thereafter == 0
+}
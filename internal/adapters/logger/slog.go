@@ -1,14 +1,105 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
 )
 
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Options configures the application logger.
+type Options struct {
+	// Level is shared with the admin endpoint so it can be changed at runtime.
+	Level     *slog.LevelVar
+	Format    string
+	AddSource bool
+	// File, when its Path is set, replaces stdout as the log output.
+	File     FileOptions
+	Sampling SamplingOptions
+}
+
+// FileOptions configures a log file rotated by size.
+type FileOptions struct {
+	Path       string
+	MaxSizeMB  int
+	MaxBackups int
+}
+
+// SamplingOptions limits repetitive Info and Debug logs: within every Tick,
+// the first Initial records with a given message are kept, then one out of
+// every Thereafter. Warnings and errors are never sampled. Initial 0 disables
+// sampling.
+type SamplingOptions struct {
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+}
+
+func DefaultOptions() Options {
+	return Options{Level: new(slog.LevelVar), Format: FormatJSON, AddSource: true}
+}
+
+// NewSlogLogger returns the default JSON logger on stdout at Info level, used
+// until the configuration is loaded.
 func NewSlogLogger() *slog.Logger {
-	//TODO could be read from .env to better handle when DEBUG like logs are required
-	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		AddSource: true,
-		Level:     slog.LevelInfo, // Set your desired log level
-	}))
+	log, _, _ := New(DefaultOptions())
+	return log
+}
+
+// New builds the logger described by opts. The returned closer releases the
+// log file, if any.
+func New(opts Options) (*slog.Logger, io.Closer, error) {
+	if opts.Level == nil {
+		opts.Level = new(slog.LevelVar)
+	}
+
+	var output io.WriteCloser = nopCloser{os.Stdout}
+	if opts.File.Path != "" {
+		file, err := newRotatingFile(opts.File)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		output = file
+	}
+
+	handlerOpts := &slog.HandlerOptions{AddSource: opts.AddSource, Level: opts.Level}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(output, handlerOpts)
+	case FormatText:
+		handler = slog.NewTextHandler(output, handlerOpts)
+	default:
+		output.Close()
+		return nil, nil, fmt.Errorf("unknown log format %q, expected %s or %s", opts.Format, FormatJSON, FormatText)
+	}
+
+	if opts.Sampling.Initial > 0 {
+		handler = newSamplingHandler(handler, opts.Sampling)
+	}
+	return slog.New(NewContextHandler(handler)), output, nil
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN".
+func ParseLevel(level string) (slog.Level, error) {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(level)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", level)
+	}
+	return parsed, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
 }
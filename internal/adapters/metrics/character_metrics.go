@@ -0,0 +1,27 @@
+package metrics
+
+import (
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type characterMetrics struct {
+	lookups *prometheus.CounterVec
+}
+
+// NewCharacterMetrics counts how character lookups are resolved, telling
+// local hits apart from upstream fetches and unknown characters.
+func NewCharacterMetrics(registerer prometheus.Registerer) *characterMetrics {
+	return &characterMetrics{
+		lookups: Register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "characters",
+			Name:      "lookups_total",
+			Help:      "Character lookups by operation and outcome (local_hit, cache_hit, stale_hit, refreshed, upstream_fetch, not_found, known_unknown, coalesced).",
+		}, []string{"operation", "outcome"})),
+	}
+}
+
+func (m *characterMetrics) RecordLookup(operation string, outcome domain.LookupOutcome) {
+	m.lookups.WithLabelValues(operation, string(outcome)).Inc()
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes every metric exposed by the service.
+const Namespace = "characters_api"
+
+// NewRegistry returns a registry holding the Go runtime and process collectors.
+// Adapters register their own collectors on it.
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// Handler serves the gathered metrics in the Prometheus text format.
+func Handler(gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+}
+
+// Register registers collector and returns it, or the equivalent collector
+// registered earlier, so adapters built twice against the same registry share
+// their metrics. A nil registerer leaves the collector unregistered.
+func Register[T prometheus.Collector](registerer prometheus.Registerer, collector T) T {
+	if registerer == nil {
+		return collector
+	}
+	if err := registerer.Register(collector); err != nil {
+		var alreadyRegistered prometheus.AlreadyRegisteredError
+		if errors.As(err, &alreadyRegistered) {
+			if existing, ok := alreadyRegistered.ExistingCollector.(T); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return collector
+}
@@ -0,0 +1,24 @@
+package http
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminAuth only lets through requests sending token as a bearer token in
+// the Authorization header. The others are answered with a 401 problem.
+func AdminAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		presented, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			c.Header("WWW-Authenticate", `Bearer realm="admin"`)
+			writeProblem(c, newProblem(http.StatusUnauthorized, CodeUnauthorized, "A valid admin token is required."))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
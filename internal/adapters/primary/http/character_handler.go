@@ -2,6 +2,8 @@ package http
 
 import (
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"log/slog"
 
@@ -25,18 +27,108 @@ func NewCharacterHandler(characterService ports.CharacterService, logger *slog.L
 func (h *CharacterHandler) CreateCharacter(c *gin.Context) {
 	var req domain.NewCharacterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("Invalid request payload for CreateCharacter", slog.String("error", err.Error()))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		h.logger.WarnContext(c.Request.Context(), "Invalid request payload for CreateCharacter", slog.String("error", err.Error()))
+		writeProblem(c, problemForBindError(err))
 		return
 	}
 
-	character, err := h.characterService.CreateCharacter(req.Name)
+	character, created, err := h.characterService.CreateCharacter(c.Request.Context(), req.Name)
 	if err != nil {
-		h.logger.Error("Failed to create/retrieve character", slog.String("error", err.Error()), slog.String("character_name", req.Name))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		h.logger.ErrorContext(c.Request.Context(), "Failed to create/retrieve character", slog.String("error", err.Error()), slog.String("character_name", req.Name))
+		writeProblem(c, problemForError(err))
 		return
 	}
 
-	h.logger.Info("Character processed successfully", slog.String("character_name", character.Name), slog.String("character_id", character.ID))
+	h.logger.InfoContext(c.Request.Context(), "Character processed successfully", slog.String("character_name", character.Name), slog.String("character_id", character.ID), slog.Bool("created", created))
+	setAgeHeader(c, character.Cache)
+	if !created {
+		c.JSON(http.StatusOK, character)
+		return
+	}
+	c.Header("Location", "/characters/"+url.PathEscape(character.ID))
 	c.JSON(http.StatusCreated, character)
 }
+
+func (h *CharacterHandler) GetCharacter(c *gin.Context) {
+	id := c.Param("id")
+
+	character, err := h.characterService.GetCharacter(c.Request.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to retrieve character", slog.String("error", err.Error()), slog.String("character_id", id))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Character retrieved successfully", slog.String("character_name", character.Name), slog.String("character_id", character.ID))
+	setAgeHeader(c, character.Cache)
+	c.JSON(http.StatusOK, character)
+}
+
+func (h *CharacterHandler) ListCharacters(c *gin.Context) {
+	params, err := parseCharacterListParams(c)
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid query parameters for ListCharacters", slog.String("error", err.Error()))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	page, err := h.characterService.ListCharacters(c.Request.Context(), params)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list characters", slog.String("error", err.Error()))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Characters listed successfully", slog.Int("count", len(page.Items)))
+	c.JSON(http.StatusOK, page)
+}
+
+// parseCharacterListParams reads the filters, sort and page of a character
+// list from the query string.
+func parseCharacterListParams(c *gin.Context) (domain.CharacterListParams, error) {
+	params := domain.CharacterListParams{
+		Race:       c.Query("race"),
+		NamePrefix: c.Query("name_prefix"),
+		Cursor:     c.Query("cursor"),
+	}
+
+	var err error
+	params.SortBy, params.Descending, err = domain.ParseCharacterSort(c.Query("sort"))
+	if err != nil {
+		return params, err
+	}
+	if limit := c.Query("limit"); limit != "" {
+		params.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			return params, domain.NewValidationError("limit", "must be an integer")
+		}
+	}
+	if params.MinKi, err = parseKiBound(c, "min_ki"); err != nil {
+		return params, err
+	}
+	if params.MaxKi, err = parseKiBound(c, "max_ki"); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// parseKiBound reads the optional power level bound of the query parameter name.
+func parseKiBound(c *gin.Context, name string) (*domain.Ki, error) {
+	text := c.Query(name)
+	if text == "" {
+		return nil, nil
+	}
+	ki, err := domain.ParseKi(text)
+	if err != nil {
+		return nil, domain.NewValidationError(name, "must be a power level such as 9000, 60.000.000 or 2.5 Billion")
+	}
+	return &ki, nil
+}
+
+// setAgeHeader tells how old the data served is, in seconds, like a shared
+// cache would.
+func setAgeHeader(c *gin.Context, cache *domain.CacheInfo) {
+	if cache != nil {
+		c.Header("Age", strconv.FormatInt(cache.AgeSeconds, 10))
+	}
+}
@@ -0,0 +1,127 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// checkTimeout bounds each dependency probe of a readiness request.
+const checkTimeout = 2 * time.Second
+
+const (
+	statusReady        = "ready"
+	statusDegraded     = "degraded"
+	statusNotReady     = "not_ready"
+	statusShuttingDown = "shutting_down"
+	statusUp           = "up"
+	statusDown         = "down"
+)
+
+// DependencyCheck is a dependency probed by the readiness endpoint. A failing
+// critical dependency makes the service not ready, any other one only
+// degrades it.
+type DependencyCheck struct {
+	Checker  ports.HealthChecker
+	Critical bool
+}
+
+type dependencyStatus struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status string                      `json:"status"`
+	Checks map[string]dependencyStatus `json:"checks,omitempty"`
+}
+
+type HealthHandler struct {
+	checks       []DependencyCheck
+	shuttingDown atomic.Bool
+	logger       *slog.Logger
+}
+
+func NewHealthHandler(logger *slog.Logger, checks ...DependencyCheck) *HealthHandler {
+	return &HealthHandler{checks: checks, logger: logger}
+}
+
+// SetShuttingDown makes readiness fail from now on, so load balancers stop
+// routing new requests while in-flight ones drain.
+func (h *HealthHandler) SetShuttingDown() {
+	if !h.shuttingDown.Swap(true) {
+		h.logger.Info("Readiness set to failing, service is shutting down")
+	}
+}
+
+// Liveness reports that the process is up and serving HTTP.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readiness probes every dependency concurrently and reports whether the
+// service should receive traffic, with a per-dependency breakdown.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, readinessResponse{Status: statusShuttingDown})
+		return
+	}
+
+	response := readinessResponse{Status: statusReady, Checks: make(map[string]dependencyStatus, len(h.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range h.checks {
+		wg.Add(1)
+		go func(check DependencyCheck) {
+			defer wg.Done()
+			result := h.probe(c.Request.Context(), check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			response.Checks[check.Checker.Name()] = result
+			if result.Status == statusUp {
+				return
+			}
+			if check.Critical {
+				response.Status = statusNotReady
+			} else if response.Status == statusReady {
+				response.Status = statusDegraded
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	if response.Status == statusNotReady {
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *HealthHandler) probe(ctx context.Context, check DependencyCheck) dependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Checker.Check(ctx)
+	result := dependencyStatus{
+		Status:    statusUp,
+		Critical:  check.Critical,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		h.logger.WarnContext(ctx, "Readiness check failed", slog.String("dependency", check.Checker.Name()), slog.String("error", err.Error()))
+		result.Status = statusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/adapters/logger"
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+type logLevelRequest struct {
+	Level string `json:"level" binding:"required"`
+}
+
+type logLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// LogLevelHandler reads and changes the log level at runtime.
+type LogLevelHandler struct {
+	level  *slog.LevelVar
+	logger *slog.Logger
+}
+
+func NewLogLevelHandler(level *slog.LevelVar, logger *slog.Logger) *LogLevelHandler {
+	return &LogLevelHandler{level: level, logger: logger}
+}
+
+func (h *LogLevelHandler) GetLevel(c *gin.Context) {
+	c.JSON(http.StatusOK, logLevelResponse{Level: h.level.Level().String()})
+}
+
+func (h *LogLevelHandler) SetLevel(c *gin.Context) {
+	var req logLevelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid request payload for SetLevel", slog.String("error", err.Error()))
+		writeProblem(c, problemForBindError(err))
+		return
+	}
+
+	level, err := logger.ParseLevel(strings.TrimSpace(req.Level))
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Rejected unknown log level", slog.String("level", req.Level))
+		writeProblem(c, problemForError(domain.NewValidationError("level", "must be one of debug, info, warn or error")))
+		return
+	}
+
+	previous := h.level.Level()
+	h.level.Set(level)
+	// Logged at Warn so the change is visible whatever the new level is
+	h.logger.WarnContext(c.Request.Context(), "Log level changed", slog.String("from", previous.String()), slog.String("to", level.String()))
+	c.JSON(http.StatusOK, logLevelResponse{Level: level.String()})
+}
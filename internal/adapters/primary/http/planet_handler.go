@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/domain"
+	"backend.go.characters.api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type planetListResponse struct {
+	Items []*domain.Planet `json:"items"`
+}
+
+type PlanetHandler struct {
+	planetService ports.PlanetService
+	logger        *slog.Logger
+}
+
+func NewPlanetHandler(planetService ports.PlanetService, logger *slog.Logger) *PlanetHandler {
+	return &PlanetHandler{
+		planetService: planetService,
+		logger:        logger,
+	}
+}
+
+func (h *PlanetHandler) ListPlanets(c *gin.Context) {
+	planets, err := h.planetService.ListPlanets(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list planets", slog.String("error", err.Error()))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Planets listed successfully", slog.Int("count", len(planets)))
+	c.JSON(http.StatusOK, planetListResponse{Items: planets})
+}
+
+func (h *PlanetHandler) GetPlanet(c *gin.Context) {
+	id := c.Param("id")
+	planet, err := h.planetService.GetPlanet(c.Request.Context(), id)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to retrieve planet", slog.String("error", err.Error()), slog.String("planet_id", id))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Planet retrieved successfully", slog.String("planet_name", planet.Name), slog.String("planet_id", planet.ID))
+	setAgeHeader(c, planet.Cache)
+	c.JSON(http.StatusOK, planet)
+}
+
+// ListPlanetCharacters takes the query parameters of the character list.
+func (h *PlanetHandler) ListPlanetCharacters(c *gin.Context) {
+	id := c.Param("id")
+	params, err := parseCharacterListParams(c)
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Invalid query parameters for ListPlanetCharacters", slog.String("error", err.Error()))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	page, err := h.planetService.ListPlanetCharacters(c.Request.Context(), id, params)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to list planet characters", slog.String("error", err.Error()), slog.String("planet_id", id))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	h.logger.InfoContext(c.Request.Context(), "Planet characters listed successfully", slog.String("planet_id", id), slog.Int("count", len(page.Items)))
+	c.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const problemContentType = "application/problem+json"
+
+// Stable, machine-readable problem codes. Clients should switch on these
+// rather than on titles or details, which are meant for humans.
+const (
+	CodeMalformedRequest    = "malformed_request"
+	CodeValidationFailed    = "validation_failed"
+	CodeUnauthorized        = "unauthorized"
+	CodeNotFound            = "not_found"
+	CodeConflict            = "conflict"
+	CodeUpstreamUnavailable = "upstream_unavailable"
+	CodeUpstreamTimeout     = "upstream_timeout"
+	CodeInternalError       = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details object extended with a stable error
+// code and, for validation failures, the offending fields.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func init() {
+	// Report request fields by their JSON name instead of the Go field name.
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+func newProblem(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   "/problems/" + strings.ReplaceAll(code, "_", "-"),
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// problemForError maps the domain errors returned by the core onto problems.
+// Details are fixed per kind so internal error wrapping never reaches clients;
+// the full error is expected to be logged by the caller.
+func problemForError(err error) *Problem {
+	var validationErr *domain.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		problem := newProblem(http.StatusUnprocessableEntity, CodeValidationFailed, "The request contains invalid values.")
+		problem.Errors = []FieldError{{Field: validationErr.Field, Message: validationErr.Message}}
+		return problem
+	case errors.Is(err, domain.ErrValidation):
+		return newProblem(http.StatusUnprocessableEntity, CodeValidationFailed, "The request contains invalid values.")
+	case errors.Is(err, domain.ErrNotFound):
+		return newProblem(http.StatusNotFound, CodeNotFound, "The requested resource was not found.")
+	case errors.Is(err, domain.ErrConflict):
+		return newProblem(http.StatusConflict, CodeConflict, "The resource conflicts with one that already exists.")
+	case errors.Is(err, domain.ErrUpstreamTimeout):
+		return newProblem(http.StatusGatewayTimeout, CodeUpstreamTimeout, "The Dragon Ball API did not respond in time.")
+	case errors.Is(err, domain.ErrUpstreamUnavailable):
+		return newProblem(http.StatusBadGateway, CodeUpstreamUnavailable, "The Dragon Ball API is currently unavailable.")
+	default:
+		return newProblem(http.StatusInternalServerError, CodeInternalError, "An unexpected error occurred.")
+	}
+}
+
+// problemForBindError turns a ShouldBind failure into a problem, listing each
+// invalid field when the body was well-formed JSON.
+func problemForBindError(err error) *Problem {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		problem := newProblem(http.StatusUnprocessableEntity, CodeValidationFailed, "The request contains invalid values.")
+		for _, fieldErr := range validationErrs {
+			problem.Errors = append(problem.Errors, FieldError{Field: fieldErr.Field(), Message: validationMessage(fieldErr)})
+		}
+		return problem
+	case errors.As(err, &typeErr):
+		problem := newProblem(http.StatusUnprocessableEntity, CodeValidationFailed, "The request contains invalid values.")
+		problem.Errors = []FieldError{{Field: typeErr.Field, Message: fmt.Sprintf("must be of type %s", typeErr.Type)}}
+		return problem
+	default:
+		return newProblem(http.StatusBadRequest, CodeMalformedRequest, "The request body is not valid JSON.")
+	}
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	default:
+		return fmt.Sprintf("failed the '%s' validation", fieldErr.Tag())
+	}
+}
+
+// writeProblem renders the problem as application/problem+json for the
+// current request.
+func writeProblem(c *gin.Context, problem *Problem) {
+	if problem.Instance == "" {
+		problem.Instance = c.Request.URL.Path
+	}
+	c.Header("Content-Type", problemContentType)
+	c.JSON(problem.Status, problem)
+}
@@ -0,0 +1,68 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// abortedKey marks in the gin context a request whose handler panicked with
+// http.ErrAbortHandler.
+const abortedKey = "recovery.aborted"
+
+// Recovery turns a panic in a handler into a 500 problem response and logs
+// it with its stack trace. A handler aborting with http.ErrAbortHandler gets
+// no response: the request is marked for PropagateAbort and the middlewares
+// in between record it as it is.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if err, ok := recovered.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				logger.WarnContext(c.Request.Context(), "Request aborted", slog.String("path", c.Request.URL.Path))
+				span := trace.SpanFromContext(c.Request.Context())
+				span.RecordError(err)
+				span.SetStatus(codes.Error, "request aborted")
+				c.Set(abortedKey, true)
+				c.Abort()
+				return
+			}
+
+			logger.ErrorContext(c.Request.Context(), "Recovered from panic",
+				slog.String("panic", fmt.Sprint(recovered)),
+				slog.String("method", c.Request.Method),
+				slog.String("path", c.Request.URL.Path),
+				slog.String("stack", string(debug.Stack())),
+			)
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			writeProblem(c, problemForError(fmt.Errorf("panic: %v", recovered)))
+			c.Abort()
+		}()
+		c.Next()
+	}
+}
+
+// PropagateAbort panics again with http.ErrAbortHandler for a request
+// Recovery marked aborted, once every other middleware is done with it, so
+// net/http aborts the response without logging. It must come first.
+func PropagateAbort() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if c.GetBool(abortedKey) {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
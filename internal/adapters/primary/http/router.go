@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/adapters/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RouterConfig gathers the handlers and settings the router is built from.
+type RouterConfig struct {
+	Logger           *slog.Logger
+	MetricsRegistry  *prometheus.Registry
+	CharacterHandler *CharacterHandler
+	PlanetHandler    *PlanetHandler
+	HealthHandler    *HealthHandler
+	LogLevelHandler  *LogLevelHandler
+	// UnknownNamesHandler serves the purge of the names unknown upstream.
+	UnknownNamesHandler *UnknownNamesHandler
+	// AdminToken is the bearer token required by the /admin routes, which
+	// are not served at all when it is empty.
+	AdminToken string
+	// AccessLogSkipPaths are not access logged, typically probes and scrapes.
+	AccessLogSkipPaths []string
+}
+
+// NewRouter builds the gin engine serving the API. Every request is traced,
+// tagged with a request ID, access logged, measured and protected against
+// handler panics.
+func NewRouter(cfg RouterConfig) *gin.Engine {
+	router := gin.New()
+	router.Use(
+		// Outermost, so an aborted request is traced, logged and measured first
+		PropagateAbort(),
+		Tracing(),
+		RequestID(),
+		AccessLog(cfg.Logger, cfg.AccessLogSkipPaths...),
+		Metrics(cfg.MetricsRegistry),
+		// Innermost, so the middlewares above see the 500 of a recovered panic
+		Recovery(cfg.Logger),
+	)
+	router.NoRoute(func(c *gin.Context) {
+		writeProblem(c, newProblem(http.StatusNotFound, CodeNotFound, "The requested resource was not found."))
+	})
+
+	router.GET("/metrics", gin.WrapH(metrics.Handler(cfg.MetricsRegistry)))
+	router.GET("/healthz", cfg.HealthHandler.Liveness)
+	router.GET("/readyz", cfg.HealthHandler.Readiness)
+	if cfg.AdminToken != "" {
+		admin := router.Group("/admin", AdminAuth(cfg.AdminToken))
+		admin.GET("/log-level", cfg.LogLevelHandler.GetLevel)
+		admin.PUT("/log-level", cfg.LogLevelHandler.SetLevel)
+		admin.DELETE("/unknown-names", cfg.UnknownNamesHandler.Purge)
+	}
+	router.POST("/characters", cfg.CharacterHandler.CreateCharacter)
+	router.GET("/characters", cfg.CharacterHandler.ListCharacters)
+	router.GET("/characters/:id", cfg.CharacterHandler.GetCharacter)
+	router.GET("/planets", cfg.PlanetHandler.ListPlanets)
+	router.GET("/planets/:id", cfg.PlanetHandler.GetPlanet)
+	router.GET("/planets/:id/characters", cfg.PlanetHandler.ListPlanetCharacters)
+	return router
+}
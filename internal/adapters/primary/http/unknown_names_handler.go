@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type purgeUnknownNamesResponse struct {
+	Purged int64 `json:"purged"`
+}
+
+// UnknownNamesHandler manages the names recorded as unknown to the external
+// API, answered as not found until they expire.
+type UnknownNamesHandler struct {
+	characterService ports.CharacterService
+	logger           *slog.Logger
+}
+
+func NewUnknownNamesHandler(characterService ports.CharacterService, logger *slog.Logger) *UnknownNamesHandler {
+	return &UnknownNamesHandler{characterService: characterService, logger: logger}
+}
+
+// Purge forgets every recorded name, so the next lookups ask the external API
+// again, typically once it learned about new characters.
+func (h *UnknownNamesHandler) Purge(c *gin.Context) {
+	purged, err := h.characterService.PurgeUnknownNames(c.Request.Context())
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to purge unknown character names", slog.String("error", err.Error()))
+		writeProblem(c, problemForError(err))
+		return
+	}
+
+	// Logged at Warn like other admin changes, so it is visible whatever the level
+	h.logger.WarnContext(c.Request.Context(), "Unknown character names purged", slog.Int64("purged", purged))
+	c.JSON(http.StatusOK, purgeUnknownNamesResponse{Purged: purged})
+}
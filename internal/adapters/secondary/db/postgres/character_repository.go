@@ -3,59 +3,463 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
 	"log/slog"
 
 	"backend.go.characters.api/internal/core/domain"
-	_ "github.com/lib/pq" // PostgreSQL driver
+	"github.com/lib/pq" // PostgreSQL driver
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// uniqueViolation is the PostgreSQL error code raised by unique constraints.
+const uniqueViolation = "23505"
+
+// defaultQueryTimeout bounds every query on top of the caller's deadline.
+const defaultQueryTimeout = 5 * time.Second
+
 type characterRepository struct {
-	db     *sql.DB
-	logger *slog.Logger
+	db           *sql.DB
+	logger       *slog.Logger
+	queryTimeout time.Duration
+	metrics      *repositoryMetrics
 }
 
-func NewCharacterRepository(db *sql.DB, logger *slog.Logger) *characterRepository {
-	return &characterRepository{db: db, logger: logger}
+// NewCharacterRepository builds a repository on db. Query latencies and
+// connection pool statistics are registered on registerer, which may be nil.
+func NewCharacterRepository(db *sql.DB, logger *slog.Logger, registerer prometheus.Registerer) *characterRepository {
+	return &characterRepository{
+		db:           db,
+		logger:       logger,
+		queryTimeout: defaultQueryTimeout,
+		metrics:      newRepositoryMetrics(db, registerer),
+	}
 }
 
-func (r *characterRepository) SaveCharacter(character *domain.Character) error {
-	ctx, cancel := context.WithCancel(context.Background())
+func (r *characterRepository) SaveCharacter(ctx context.Context, character *domain.Character) error {
+	ctx, span := startQuerySpan(ctx, "SaveCharacter", "INSERT")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
 	defer cancel()
+	defer r.metrics.observeQuery("save_character", time.Now())
 
 	query := `
-		INSERT INTO characters (id, name, ki, race, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, NOW(), NOW())
+		INSERT INTO characters (id, name, ki, ki_magnitude, ki_significand, max_ki, race, gender, description,
+			image_url, affiliation, deleted_at, origin_planet_id, transformations, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NOW(), NOW())
 		ON CONFLICT (id) DO UPDATE
-		SET name = EXCLUDED.name, ki = EXCLUDED.ki, race = EXCLUDED.race, updated_at = NOW();
+		SET name = EXCLUDED.name, ki = EXCLUDED.ki, ki_magnitude = EXCLUDED.ki_magnitude,
+			ki_significand = EXCLUDED.ki_significand, max_ki = EXCLUDED.max_ki, race = EXCLUDED.race,
+			gender = EXCLUDED.gender, description = EXCLUDED.description, image_url = EXCLUDED.image_url,
+			affiliation = EXCLUDED.affiliation, deleted_at = EXCLUDED.deleted_at,
+			origin_planet_id = COALESCE(EXCLUDED.origin_planet_id, characters.origin_planet_id),
+			transformations = EXCLUDED.transformations, updated_at = NOW()
+		RETURNING created_at, updated_at, origin_planet_id;
 	`
-	_, err := r.db.ExecContext(ctx, query, character.ID, character.Name, character.Ki, character.Race)
+	transformations, err := encodeTransformations(character.Transformations)
+	if err != nil {
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to save character: %w", err)
+	}
+	magnitude, significand := kiSortKey(character.Ki)
+
+	// The origin planet is saved first for the character to reference it
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to save character: %w", err)
+	}
+	defer tx.Rollback()
+
+	var originPlanetID *string
+	if character.OriginPlanet != nil {
+		if err := upsertPlanet(ctx, tx, character.OriginPlanet, false); err != nil {
+			r.logger.ErrorContext(ctx, "Failed to save origin planet to database", slog.String("error", err.Error()), slog.String("character_id", character.ID), slog.String("planet_id", character.OriginPlanet.ID))
+			recordSpanError(span, err)
+			return fmt.Errorf("failed to save origin planet: %w", err)
+		}
+		originPlanetID = &character.OriginPlanet.ID
+	}
+	// The stored timestamps are reported back, so the caller holds the row as
+	// saved. A profile without an origin planet keeps the one linked before.
+	var storedPlanetID sql.NullString
+	err = tx.QueryRowContext(ctx, query, character.ID, character.Name, character.Ki.String(), magnitude, significand,
+		character.MaxKi.String(), character.Race, character.Gender, character.Description, character.ImageURL,
+		character.Affiliation, character.DeletedAt, originPlanetID, transformations).
+		Scan(&character.CreatedAt, &character.UpdatedAt, &storedPlanetID)
+	if err == nil && character.OriginPlanet == nil && storedPlanetID.Valid {
+		character.OriginPlanet, err = scanPlanet(tx.QueryRowContext(ctx, `SELECT `+planetColumns+` FROM planets WHERE id = $1;`, storedPlanetID.String))
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
 	if err != nil {
-		r.logger.Error("Failed to save character to database", slog.String("error", err.Error()), slog.String("character_id", character.ID))
+		r.logger.ErrorContext(ctx, "Failed to save character to database", slog.String("error", err.Error()), slog.String("character_id", character.ID))
+		recordSpanError(span, err)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return fmt.Errorf("failed to save character: %w: %w", domain.ErrConflict, err)
+		}
 		return fmt.Errorf("failed to save character: %w", err)
 	}
-	r.logger.Info("Character saved successfully to database", slog.String("character_id", character.ID))
+	r.logger.InfoContext(ctx, "Character saved successfully to database", slog.String("character_id", character.ID))
 	return nil
 }
 
-func (r *characterRepository) FindCharacterByName(name string) (*domain.Character, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+func (r *characterRepository) FindCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
+	ctx, span := startQuerySpan(ctx, "FindCharacterByName", "SELECT")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
 	defer cancel()
+	defer r.metrics.observeQuery("find_character_by_name", time.Now())
 
-	query := `SELECT id, name, ki, race, created_at, updated_at FROM characters WHERE name ILIKE $1;`
+	// An exact match ignoring case, % and _ in name are not wildcards
+	query := `SELECT ` + characterColumns + ` FROM ` + characterSource + ` WHERE LOWER(c.name) = LOWER($1);`
 	row := r.db.QueryRowContext(ctx, query, name)
 
-	character := &domain.Character{}
-	err := row.Scan(&character.ID, &character.Name, &character.Ki, &character.Race, &character.CreatedAt, &character.UpdatedAt)
+	character, err := scanCharacter(row)
 	if err == sql.ErrNoRows {
-		r.logger.Info("Character not found in database by name", slog.String("character_name", name))
+		r.logger.InfoContext(ctx, "Character not found in database by name", slog.String("character_name", name))
 		return nil, nil // Character not found
 	}
 	if err != nil {
-		r.logger.Error("Failed to query character by name from database", slog.String("error", err.Error()), slog.String("character_name", name))
+		r.logger.ErrorContext(ctx, "Failed to query character by name from database", slog.String("error", err.Error()), slog.String("character_name", name))
+		recordSpanError(span, err)
 		return nil, fmt.Errorf("failed to find character by name: %w", err)
 	}
-	r.logger.Info("Character found in database by name", slog.String("character_name", name), slog.String("character_id", character.ID))
+	r.logger.InfoContext(ctx, "Character found in database by name", slog.String("character_name", name), slog.String("character_id", character.ID))
 	return character, nil
 }
+
+func (r *characterRepository) FindCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	ctx, span := startQuerySpan(ctx, "FindCharacterByID", "SELECT")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("find_character_by_id", time.Now())
+
+	query := `SELECT ` + characterColumns + ` FROM ` + characterSource + ` WHERE c.id = $1;`
+	row := r.db.QueryRowContext(ctx, query, id)
+
+	character, err := scanCharacter(row)
+	if err == sql.ErrNoRows {
+		r.logger.InfoContext(ctx, "Character not found in database by ID", slog.String("character_id", id))
+		return nil, nil // Character not found
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to query character by ID from database", slog.String("error", err.Error()), slog.String("character_id", id))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to find character by ID: %w", err)
+	}
+	r.logger.InfoContext(ctx, "Character found in database by ID", slog.String("character_id", id), slog.String("character_name", character.Name))
+	return character, nil
+}
+
+// Unknown power levels sort before zero, which sorts before every positive
+// one. Rows saved before the ki columns existed get their key from
+// BackfillKiSortKeys.
+const (
+	kiMagnitudeUnknown = "-2"
+	kiMagnitudeZero    = "-1"
+)
+
+// kiSortKey returns the ki_magnitude and ki_significand column values of ki,
+// which order the rows by the value of their power level.
+func kiSortKey(ki domain.Ki) (string, string) {
+	switch {
+	case !ki.Known():
+		return kiMagnitudeUnknown, "0"
+	case ki.IsZero():
+		return kiMagnitudeZero, "0"
+	}
+	return ki.Magnitude().String(), ki.Significand()
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// characterColumns are read by every query returning characters, in the
+// order scanCharacter expects them, from characterSource.
+const (
+	characterColumns = `c.id, c.name, c.ki, c.max_ki, c.race, c.gender, c.description, c.image_url, c.affiliation, c.deleted_at, c.transformations, c.created_at, c.updated_at, ` +
+		`p.id, p.name, p.is_destroyed, p.description, p.image_url, p.deleted_at, p.created_at, p.updated_at`
+	characterSource = `characters c LEFT JOIN planets p ON p.id = c.origin_planet_id`
+)
+
+func scanCharacter(row rowScanner) (*domain.Character, error) {
+	character := &domain.Character{}
+	var ki, maxKi string
+	var deletedAt sql.NullTime
+	var transformations []byte
+	var planet nullablePlanet
+	if err := row.Scan(&character.ID, &character.Name, &ki, &maxKi, &character.Race, &character.Gender,
+		&character.Description, &character.ImageURL, &character.Affiliation, &deletedAt, &transformations,
+		&character.CreatedAt, &character.UpdatedAt, &planet.ID, &planet.Name, &planet.IsDestroyed,
+		&planet.Description, &planet.ImageURL, &planet.DeletedAt, &planet.CreatedAt, &planet.UpdatedAt); err != nil {
+		return nil, err
+	}
+	character.Ki = domain.NewKi(ki)
+	character.MaxKi = domain.NewKi(maxKi)
+	if deletedAt.Valid {
+		character.DeletedAt = &deletedAt.Time
+	}
+	character.OriginPlanet = planet.toDomain()
+	if transformations != nil {
+		if err := json.Unmarshal(transformations, &character.Transformations); err != nil {
+			return nil, fmt.Errorf("failed to decode transformations: %w", err)
+		}
+	}
+	return character, nil
+}
+
+// nullablePlanet scans the planet columns of a character without an origin
+// planet, which the join leaves NULL.
+type nullablePlanet struct {
+	ID, Name, Description, ImageURL sql.NullString
+	IsDestroyed                     sql.NullBool
+	DeletedAt, CreatedAt, UpdatedAt sql.NullTime
+}
+
+func (p nullablePlanet) toDomain() *domain.Planet {
+	if !p.ID.Valid {
+		return nil
+	}
+	planet := &domain.Planet{
+		ID:          p.ID.String,
+		Name:        p.Name.String,
+		IsDestroyed: p.IsDestroyed.Bool,
+		Description: p.Description.String,
+		ImageURL:    p.ImageURL.String,
+		CreatedAt:   p.CreatedAt.Time,
+		UpdatedAt:   p.UpdatedAt.Time,
+	}
+	if p.DeletedAt.Valid {
+		planet.DeletedAt = &p.DeletedAt.Time
+	}
+	return planet
+}
+
+func encodeTransformations(transformations []domain.Transformation) ([]byte, error) {
+	if transformations == nil {
+		transformations = []domain.Transformation{}
+	}
+	data, err := json.Marshal(transformations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transformations: %w", err)
+	}
+	return data, nil
+}
+
+// listCursor is the keyset position encoded into CharacterPage.NextCursor:
+// the sort column value and the id of the last row of the previous page.
+// Sorting by ki, Value and Significand hold the stored ki_magnitude and
+// ki_significand of the row rather than its ki text.
+type listCursor struct {
+	Value       string `json:"v"`
+	Significand string `json:"s,omitempty"`
+	ID          string `json:"id"`
+}
+
+// storedKiKey is the ki sort key of a row as stored, selected alongside
+// characterColumns when sorting by ki.
+type storedKiKey struct {
+	magnitude, significand string
+}
+
+var (
+	kiMagnitudePattern   = regexp.MustCompile(`^-?\d+$`)
+	kiSignificandPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+)
+
+func encodeListCursor(sortBy domain.CharacterSortField, character *domain.Character, kiKey storedKiKey) (string, error) {
+	cursor := listCursor{ID: character.ID}
+	switch sortBy {
+	case domain.CharacterSortByCreatedAt:
+		cursor.Value = character.CreatedAt.Format(time.RFC3339Nano)
+	case domain.CharacterSortByUpdatedAt:
+		cursor.Value = character.UpdatedAt.Format(time.RFC3339Nano)
+	case domain.CharacterSortByKi:
+		cursor.Value, cursor.Significand = kiKey.magnitude, kiKey.significand
+	default:
+		cursor.Value = character.Name
+	}
+	raw, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeListCursor returns the sort column values and the id held by token,
+// one value per column of the sort.
+func decodeListCursor(sortBy domain.CharacterSortField, token string) ([]any, string, error) {
+	invalidCursor := domain.NewValidationError("cursor", "malformed or does not match the requested sort")
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, "", invalidCursor
+	}
+	var cursor listCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, "", invalidCursor
+	}
+	switch sortBy {
+	case domain.CharacterSortByName:
+		return []any{cursor.Value}, cursor.ID, nil
+	case domain.CharacterSortByKi:
+		// The values are bound to NUMERIC parameters, reject anything else here
+		if !kiMagnitudePattern.MatchString(cursor.Value) || !kiSignificandPattern.MatchString(cursor.Significand) {
+			return nil, "", invalidCursor
+		}
+		return []any{cursor.Value, cursor.Significand}, cursor.ID, nil
+	}
+	value, err := time.Parse(time.RFC3339Nano, cursor.Value)
+	if err != nil {
+		return nil, "", invalidCursor
+	}
+	return []any{value}, cursor.ID, nil
+}
+
+// withKiKey scans the ki sort key selected after characterColumns into key.
+type withKiKey struct {
+	rowScanner
+	key *storedKiKey
+}
+
+func (r withKiKey) Scan(dest ...any) error {
+	return r.rowScanner.Scan(append(dest, &r.key.magnitude, &r.key.significand)...)
+}
+
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (r *characterRepository) ListCharacters(ctx context.Context, params domain.CharacterListParams) (*domain.CharacterPage, error) {
+	ctx, span := startQuerySpan(ctx, "ListCharacters", "SELECT")
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("list_characters", time.Now())
+
+	// The sort columns are interpolated, so only accept known identifiers.
+	var sortColumns []string
+	switch params.SortBy {
+	case domain.CharacterSortByName, domain.CharacterSortByCreatedAt, domain.CharacterSortByUpdatedAt:
+		sortColumns = []string{"c." + string(params.SortBy)}
+	case domain.CharacterSortByKi:
+		sortColumns = []string{"c.ki_magnitude", "c.ki_significand"}
+	default:
+		return nil, domain.NewValidationError("sort", fmt.Sprintf("unsupported field '%s'", params.SortBy))
+	}
+	direction, comparator := "ASC", ">"
+	if params.Descending {
+		direction, comparator = "DESC", "<"
+	}
+
+	var conditions []string
+	var args []any
+	if params.Race != "" {
+		args = append(args, params.Race)
+		conditions = append(conditions, fmt.Sprintf("LOWER(c.race) = LOWER($%d)", len(args)))
+	}
+	if params.NamePrefix != "" {
+		args = append(args, escapeLikePattern(params.NamePrefix)+"%")
+		conditions = append(conditions, fmt.Sprintf("c.name ILIKE $%d", len(args)))
+	}
+	if params.OriginPlanetID != "" {
+		args = append(args, params.OriginPlanetID)
+		conditions = append(conditions, fmt.Sprintf("c.origin_planet_id = $%d", len(args)))
+	}
+	if params.MinKi != nil {
+		magnitude, significand := kiSortKey(*params.MinKi)
+		args = append(args, magnitude, significand)
+		conditions = append(conditions, fmt.Sprintf("(c.ki_magnitude, c.ki_significand) >= ($%d, $%d)", len(args)-1, len(args)))
+	}
+	if params.MaxKi != nil {
+		magnitude, significand := kiSortKey(*params.MaxKi)
+		args = append(args, magnitude, significand)
+		conditions = append(conditions, fmt.Sprintf("(c.ki_magnitude, c.ki_significand) <= ($%d, $%d) AND c.ki_magnitude <> %s", len(args)-1, len(args), kiMagnitudeUnknown))
+	}
+	if params.Cursor != "" {
+		values, id, err := decodeListCursor(params.SortBy, params.Cursor)
+		if err != nil {
+			r.logger.WarnContext(ctx, "Failed to decode list cursor", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("failed to list characters: %w", err)
+		}
+		placeholders := make([]string, 0, len(values)+1)
+		for _, value := range append(values, id) {
+			args = append(args, value)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s, c.id) %s (%s)", strings.Join(sortColumns, ", "), comparator, strings.Join(placeholders, ", ")))
+	}
+
+	// The cursor carries the stored ki key, which the ki text may no longer
+	// parse to, so that the next page starts right after the last row.
+	columns := characterColumns
+	if params.SortBy == domain.CharacterSortByKi {
+		columns += ", c.ki_magnitude, c.ki_significand"
+	}
+	query := `SELECT ` + columns + ` FROM ` + characterSource
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	// Fetch one extra row to know whether another page follows.
+	args = append(args, params.Limit+1)
+	var order []string
+	for _, column := range append(sortColumns, "c.id") {
+		order = append(order, column+" "+direction)
+	}
+	query += fmt.Sprintf(" ORDER BY %s LIMIT $%d;", strings.Join(order, ", "), len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to list characters from database", slog.String("error", err.Error()))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to list characters: %w", err)
+	}
+	defer rows.Close()
+
+	page := &domain.CharacterPage{Items: []*domain.Character{}}
+	var kiKeys []storedKiKey
+	for rows.Next() {
+		var scanner rowScanner = rows
+		var kiKey storedKiKey
+		if params.SortBy == domain.CharacterSortByKi {
+			scanner = withKiKey{rowScanner: rows, key: &kiKey}
+		}
+		character, err := scanCharacter(scanner)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to scan character row", slog.String("error", err.Error()))
+			recordSpanError(span, err)
+			return nil, fmt.Errorf("failed to list characters: %w", err)
+		}
+		page.Items = append(page.Items, character)
+		kiKeys = append(kiKeys, kiKey)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to iterate character rows", slog.String("error", err.Error()))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to list characters: %w", err)
+	}
+
+	if len(page.Items) > params.Limit {
+		page.Items = page.Items[:params.Limit]
+		cursor, err := encodeListCursor(params.SortBy, page.Items[len(page.Items)-1], kiKeys[len(page.Items)-1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to list characters: %w", err)
+		}
+		page.NextCursor = cursor
+	}
+
+	r.logger.InfoContext(ctx, "Characters listed from database", slog.Int("count", len(page.Items)), slog.Bool("has_more", page.NextCursor != ""))
+	return page, nil
+}
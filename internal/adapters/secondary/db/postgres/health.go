@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type databaseHealthChecker struct {
+	db *sql.DB
+}
+
+func NewDatabaseHealthChecker(db *sql.DB) *databaseHealthChecker {
+	return &databaseHealthChecker{db: db}
+}
+
+func (h *databaseHealthChecker) Name() string {
+	return "database"
+}
+
+func (h *databaseHealthChecker) Check(ctx context.Context) error {
+	if err := h.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+func (m *Migrator) Name() string {
+	return "migrations"
+}
+
+// Check fails while the schema is older than the latest embedded migration.
+// A newer schema is accepted so replicas keep serving during rolling deploys.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if expected := m.LatestVersion(); version < expected {
+		return fmt.Errorf("schema version %d is behind expected version %d", version, expected)
+	}
+	return nil
+}
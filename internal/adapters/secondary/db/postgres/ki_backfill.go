@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/domain"
+)
+
+// KiSortKeyVersion is the version of the migration adding the ki sort key,
+// whose existing rows BackfillKiSortKeys fills in.
+const KiSortKeyVersion int64 = 4
+
+// kiBackfillBatchSize bounds the rows read by each step of BackfillKiSortKeys.
+const kiBackfillBatchSize = 500
+
+// BackfillKiSortKeys sets the ki sort key of the rows stored before the
+// ki_magnitude and ki_significand columns existed, which the migration left
+// unknown. The key is computed from the ki text the way SaveCharacter does;
+// rows whose ki is not a number keep the unknown key. It returns the number
+// of rows updated and is safe to run again, or from several replicas.
+func (r *characterRepository) BackfillKiSortKeys(ctx context.Context) (int64, error) {
+	ctx, span := startQuerySpan(ctx, "BackfillKiSortKeys", "UPDATE")
+	defer span.End()
+	defer r.metrics.observeQuery("backfill_ki_sort_keys", time.Now())
+
+	var updated int64
+	lastID := ""
+	for {
+		rows, err := r.kiBackfillBatch(ctx, lastID)
+		if err != nil {
+			r.logger.ErrorContext(ctx, "Failed to read characters without a ki sort key", slog.String("error", err.Error()))
+			recordSpanError(span, err)
+			return updated, fmt.Errorf("failed to backfill ki sort keys: %w", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for _, row := range rows {
+			lastID = row.id
+			magnitude, significand := kiSortKey(domain.NewKi(row.ki))
+			if magnitude == kiMagnitudeUnknown {
+				continue
+			}
+			n, err := r.setKiSortKey(ctx, row.id, row.ki, magnitude, significand)
+			if err != nil {
+				r.logger.ErrorContext(ctx, "Failed to backfill ki sort key", slog.String("error", err.Error()), slog.String("character_id", row.id))
+				recordSpanError(span, err)
+				return updated, fmt.Errorf("failed to backfill ki sort keys: %w", err)
+			}
+			updated += n
+		}
+	}
+
+	r.logger.InfoContext(ctx, "Ki sort keys backfilled", slog.Int64("count", updated))
+	return updated, nil
+}
+
+type kiBackfillRow struct {
+	id, ki string
+}
+
+// kiBackfillBatch reads the next rows after lastID still holding the unknown key.
+func (r *characterRepository) kiBackfillBatch(ctx context.Context, lastID string) ([]kiBackfillRow, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `SELECT id, ki FROM characters WHERE ki_magnitude = ` + kiMagnitudeUnknown + ` AND ki <> '' AND id > $1 ORDER BY id LIMIT $2;`
+	rows, err := r.db.QueryContext(ctx, query, lastID, kiBackfillBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var batch []kiBackfillRow
+	for rows.Next() {
+		var row kiBackfillRow
+		if err := rows.Scan(&row.id, &row.ki); err != nil {
+			return nil, err
+		}
+		batch = append(batch, row)
+	}
+	return batch, rows.Err()
+}
+
+// setKiSortKey leaves the row alone when it was saved again in the meantime.
+func (r *characterRepository) setKiSortKey(ctx context.Context, id, ki, magnitude, significand string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `UPDATE characters SET ki_magnitude = $2, ki_significand = $3 WHERE id = $1 AND ki = $4 AND ki_magnitude = ` + kiMagnitudeUnknown + `;`
+	result, err := r.db.ExecContext(ctx, query, id, magnitude, significand, ki)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,38 @@
+package postgres
+
+import (
+	"database/sql"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// dbName labels the connection pool statistics.
+const dbName = "characters"
+
+type repositoryMetrics struct {
+	queryDuration *prometheus.HistogramVec
+}
+
+// newRepositoryMetrics registers the query latency histogram and the sql.DB
+// connection pool statistics of db.
+func newRepositoryMetrics(db *sql.DB, registerer prometheus.Registerer) *repositoryMetrics {
+	metrics.Register(registerer, collectors.NewDBStatsCollector(db, dbName))
+	return &repositoryMetrics{
+		queryDuration: metrics.Register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Database query latency by query.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"query"})),
+	}
+}
+
+// observeQuery records the time elapsed since start, meant to be deferred at
+// the top of a repository method.
+func (m *repositoryMetrics) observeQuery(query string, start time.Time) {
+	m.queryDuration.WithLabelValues(query).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,237 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/lib/pq"
+)
+
+// The characterRepository also stores the planets, in the planets table the
+// characters reference. Planets of the imported upstream list have listed_at
+// set to the time of the import, planets only saved as the origin of a
+// character, or dropped from the list since, do not.
+
+const planetColumns = `id, name, is_destroyed, description, image_url, deleted_at, characters_imported_at, created_at, updated_at`
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx.
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// upsertPlanet inserts or updates planet, marking it as listed when listed is
+// set, and reports the stored timestamps back into it.
+func upsertPlanet(ctx context.Context, q queryRower, planet *domain.Planet, listed bool) error {
+	query := `
+		INSERT INTO planets (id, name, is_destroyed, description, image_url, deleted_at, listed_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $7::BOOLEAN THEN NOW() END, NOW(), NOW())
+		ON CONFLICT (id) DO UPDATE
+		SET name = EXCLUDED.name, is_destroyed = EXCLUDED.is_destroyed, description = EXCLUDED.description,
+			image_url = EXCLUDED.image_url, deleted_at = EXCLUDED.deleted_at,
+			listed_at = COALESCE(EXCLUDED.listed_at, planets.listed_at), updated_at = NOW()
+		RETURNING created_at, updated_at;
+	`
+	return q.QueryRowContext(ctx, query, planet.ID, planet.Name, planet.IsDestroyed, planet.Description, planet.ImageURL, planet.DeletedAt, listed).
+		Scan(&planet.CreatedAt, &planet.UpdatedAt)
+}
+
+func (r *characterRepository) SavePlanet(ctx context.Context, planet *domain.Planet) error {
+	ctx, span := startTableSpan(ctx, "SavePlanet", "INSERT", planetsTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("save_planet", time.Now())
+
+	if err := upsertPlanet(ctx, r.db, planet, false); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save planet to database", slog.String("error", err.Error()), slog.String("planet_id", planet.ID))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to save planet: %w", err)
+	}
+	r.logger.InfoContext(ctx, "Planet saved successfully to database", slog.String("planet_id", planet.ID))
+	return nil
+}
+
+// SavePlanetList saves planets in one transaction, so the list is either
+// imported as a whole or not at all, and unlists the planets left out of it.
+func (r *characterRepository) SavePlanetList(ctx context.Context, planets []*domain.Planet) error {
+	ctx, span := startTableSpan(ctx, "SavePlanetList", "INSERT", planetsTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("save_planet_list", time.Now())
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to save planet list: %w", err)
+	}
+	defer tx.Rollback()
+
+	ids := make([]string, 0, len(planets))
+	for _, planet := range planets {
+		if err := upsertPlanet(ctx, tx, planet, true); err != nil {
+			r.logger.ErrorContext(ctx, "Failed to save planet to database", slog.String("error", err.Error()), slog.String("planet_id", planet.ID))
+			recordSpanError(span, err)
+			return fmt.Errorf("failed to save planet list: %w", err)
+		}
+		ids = append(ids, planet.ID)
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE planets SET listed_at = NULL WHERE listed_at IS NOT NULL AND NOT (id = ANY($1));`, pq.Array(ids)); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to unlist planets dropped from the list", slog.String("error", err.Error()))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to save planet list: %w", err)
+	}
+	// Recorded apart from the planets, so an empty list still counts as imported
+	query := `
+		INSERT INTO planet_list_imports (singleton, imported_at) VALUES (TRUE, NOW())
+		ON CONFLICT (singleton) DO UPDATE SET imported_at = EXCLUDED.imported_at;`
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to record planet list import", slog.String("error", err.Error()))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to save planet list: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to save planet list: %w", err)
+	}
+	r.logger.InfoContext(ctx, "Planet list saved successfully to database", slog.Int("count", len(planets)))
+	return nil
+}
+
+func (r *characterRepository) FindPlanetByID(ctx context.Context, id string) (*domain.Planet, error) {
+	ctx, span := startTableSpan(ctx, "FindPlanetByID", "SELECT", planetsTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("find_planet_by_id", time.Now())
+
+	query := `SELECT ` + planetColumns + ` FROM planets WHERE id = $1;`
+	planet, err := scanPlanet(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		r.logger.InfoContext(ctx, "Planet not found in database by ID", slog.String("planet_id", id))
+		return nil, nil
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to query planet by ID from database", slog.String("error", err.Error()), slog.String("planet_id", id))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to find planet by ID: %w", err)
+	}
+	return planet, nil
+}
+
+func (r *characterRepository) LinkPlanetCharacters(ctx context.Context, id string, characterIDs []string) ([]string, error) {
+	ctx, span := startTableSpan(ctx, "LinkPlanetCharacters", "UPDATE", charactersTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("link_planet_characters", time.Now())
+
+	// Characters saved before their origin planet was stored have none
+	query := `
+		WITH linked AS (
+			UPDATE characters SET origin_planet_id = $1 WHERE id = ANY($2) AND origin_planet_id IS NULL
+		)
+		SELECT id FROM characters WHERE id = ANY($2);`
+	rows, err := r.db.QueryContext(ctx, query, id, pq.Array(characterIDs))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to link planet characters", slog.String("error", err.Error()), slog.String("planet_id", id))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to link planet characters: %w", err)
+	}
+	defer rows.Close()
+
+	stored := []string{}
+	for rows.Next() {
+		var characterID string
+		if err := rows.Scan(&characterID); err != nil {
+			r.logger.ErrorContext(ctx, "Failed to scan linked planet character", slog.String("error", err.Error()), slog.String("planet_id", id))
+			recordSpanError(span, err)
+			return nil, fmt.Errorf("failed to scan linked planet character: %w", err)
+		}
+		stored = append(stored, characterID)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to link planet characters", slog.String("error", err.Error()), slog.String("planet_id", id))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to link planet characters: %w", err)
+	}
+	return stored, nil
+}
+
+func (r *characterRepository) MarkPlanetCharactersImported(ctx context.Context, id string) error {
+	ctx, span := startTableSpan(ctx, "MarkPlanetCharactersImported", "UPDATE", planetsTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("mark_planet_characters_imported", time.Now())
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE planets SET characters_imported_at = NOW() WHERE id = $1;`, id); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to mark planet characters as imported", slog.String("error", err.Error()), slog.String("planet_id", id))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to mark planet characters as imported: %w", err)
+	}
+	return nil
+}
+
+func (r *characterRepository) ListPlanets(ctx context.Context) (*domain.PlanetList, error) {
+	ctx, span := startTableSpan(ctx, "ListPlanets", "SELECT", planetsTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("list_planets", time.Now())
+
+	list := &domain.PlanetList{Planets: []*domain.Planet{}}
+	err := r.db.QueryRowContext(ctx, `SELECT imported_at FROM planet_list_imports;`).Scan(&list.ImportedAt)
+	if err == sql.ErrNoRows {
+		return list, nil
+	}
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to read planet list import from database", slog.String("error", err.Error()))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to list planets: %w", err)
+	}
+
+	query := `SELECT ` + planetColumns + ` FROM planets WHERE listed_at IS NOT NULL ORDER BY name, id;`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to list planets from database", slog.String("error", err.Error()))
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to list planets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		planet, err := scanPlanet(rows)
+		if err != nil {
+			recordSpanError(span, err)
+			return nil, fmt.Errorf("failed to list planets: %w", err)
+		}
+		list.Planets = append(list.Planets, planet)
+	}
+	if err := rows.Err(); err != nil {
+		recordSpanError(span, err)
+		return nil, fmt.Errorf("failed to list planets: %w", err)
+	}
+	return list, nil
+}
+
+func scanPlanet(row rowScanner) (*domain.Planet, error) {
+	planet := &domain.Planet{}
+	var deletedAt, charactersImportedAt sql.NullTime
+	if err := row.Scan(&planet.ID, &planet.Name, &planet.IsDestroyed, &planet.Description, &planet.ImageURL,
+		&deletedAt, &charactersImportedAt, &planet.CreatedAt, &planet.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		planet.DeletedAt = &deletedAt.Time
+	}
+	if charactersImportedAt.Valid {
+		planet.CharactersImportedAt = &charactersImportedAt.Time
+	}
+	return planet, nil
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "backend.go.characters.api/internal/adapters/secondary/db/postgres"
+
+const (
+	charactersTable   = "characters"
+	unknownNamesTable = "unknown_character_names"
+	planetsTable      = "planets"
+)
+
+// startQuerySpan starts a client span for a statement on the characters table.
+func startQuerySpan(ctx context.Context, method, operation string) (context.Context, trace.Span) {
+	return startTableSpan(ctx, method, operation, charactersTable)
+}
+
+// startTableSpan starts a client span for a statement on table, named after
+// its operation as the database semantic conventions ask.
+func startTableSpan(ctx context.Context, method, operation, table string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, operation+" "+table,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+			semconv.CodeFunction(method),
+		),
+	)
+}
+
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"log/slog"
+)
+
+// The characterRepository also records the names the external API does not
+// know, in the unknown_character_names table. Expired rows are ignored, and
+// deleted whenever a name is recorded so the table does not keep growing.
+
+func (r *characterRepository) IsUnknownName(ctx context.Context, name string) (bool, error) {
+	ctx, span := startTableSpan(ctx, "IsUnknownName", "SELECT", unknownNamesTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("is_unknown_name", time.Now())
+
+	query := `SELECT EXISTS (SELECT 1 FROM unknown_character_names WHERE name = $1 AND expires_at > NOW());`
+	var unknown bool
+	if err := r.db.QueryRowContext(ctx, query, name).Scan(&unknown); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to look up unknown character name", slog.String("error", err.Error()), slog.String("character_name", name))
+		recordSpanError(span, err)
+		return false, fmt.Errorf("failed to look up unknown character name: %w", err)
+	}
+	return unknown, nil
+}
+
+func (r *characterRepository) SaveUnknownName(ctx context.Context, name string, ttl time.Duration) error {
+	ctx, span := startTableSpan(ctx, "SaveUnknownName", "INSERT", unknownNamesTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("save_unknown_name", time.Now())
+
+	query := `
+		WITH expired AS (
+			DELETE FROM unknown_character_names WHERE expires_at <= NOW() AND name <> $1
+		)
+		INSERT INTO unknown_character_names (name, expires_at)
+		VALUES ($1, NOW() + $2 * INTERVAL '1 millisecond')
+		ON CONFLICT (name) DO UPDATE
+		SET expires_at = EXCLUDED.expires_at, created_at = NOW();
+	`
+	if _, err := r.db.ExecContext(ctx, query, name, ttl.Milliseconds()); err != nil {
+		r.logger.ErrorContext(ctx, "Failed to record unknown character name", slog.String("error", err.Error()), slog.String("character_name", name))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to record unknown character name: %w", err)
+	}
+	r.logger.InfoContext(ctx, "Unknown character name recorded", slog.String("character_name", name), slog.Duration("ttl", ttl))
+	return nil
+}
+
+func (r *characterRepository) PurgeUnknownNames(ctx context.Context) (int64, error) {
+	ctx, span := startTableSpan(ctx, "PurgeUnknownNames", "DELETE", unknownNamesTable)
+	defer span.End()
+	ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+	defer cancel()
+	defer r.metrics.observeQuery("purge_unknown_names", time.Now())
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM unknown_character_names;`)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to purge unknown character names", slog.String("error", err.Error()))
+		recordSpanError(span, err)
+		return 0, fmt.Errorf("failed to purge unknown character names: %w", err)
+	}
+	purged, err := result.RowsAffected()
+	if err != nil {
+		recordSpanError(span, err)
+		return 0, fmt.Errorf("failed to purge unknown character names: %w", err)
+	}
+	return purged, nil
+}
@@ -1,102 +1,404 @@
 package dragonballapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"log/slog"
 
 	"backend.go.characters.api/internal/core/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"golang.org/x/sync/singleflight"
 )
 
-var BaseURL string = "https://dragonball-api.com/api"
-
+// apiCharacter is a character as the upstream returns it. The character list
+// leaves out the origin planet and the transformations.
 type apiCharacter struct {
-	ID   json.Number `json:"id"`
-	Name string      `json:"name"`
-	Ki   string      `json:"ki"`
-	Race string      `json:"race"`
+	ID              json.Number         `json:"id"`
+	Name            string              `json:"name"`
+	Ki              string              `json:"ki"`
+	MaxKi           string              `json:"maxKi"`
+	Race            string              `json:"race"`
+	Gender          string              `json:"gender"`
+	Description     string              `json:"description"`
+	Image           string              `json:"image"`
+	Affiliation     string              `json:"affiliation"`
+	DeletedAt       *time.Time          `json:"deletedAt"`
+	OriginPlanet    *apiPlanet          `json:"originPlanet"`
+	Transformations []apiTransformation `json:"transformations"`
+}
+
+type apiPlanet struct {
+	ID          json.Number `json:"id"`
+	Name        string      `json:"name"`
+	IsDestroyed bool        `json:"isDestroyed"`
+	Description string      `json:"description"`
+	Image       string      `json:"image"`
+	DeletedAt   *time.Time  `json:"deletedAt"`
+	// Characters is only returned by the planet lookup.
+	Characters []apiCharacter `json:"characters"`
+}
+
+func (a *apiPlanet) toDomain() *domain.Planet {
+	return &domain.Planet{
+		ID:          a.ID.String(),
+		Name:        a.Name,
+		IsDestroyed: a.IsDestroyed,
+		Description: a.Description,
+		ImageURL:    a.Image,
+		DeletedAt:   a.DeletedAt,
+	}
 }
 
-type apiCharactersResponse struct {
-	Items []apiCharacter `json:"items"`
+type apiTransformation struct {
+	ID        json.Number `json:"id"`
+	Name      string      `json:"name"`
+	Image     string      `json:"image"`
+	Ki        string      `json:"ki"`
+	DeletedAt *time.Time  `json:"deletedAt"`
 }
 
+func (a *apiCharacter) toDomain() *domain.Character {
+	character := &domain.Character{
+		ID:          a.ID.String(), // Convert json.Number to string
+		Name:        a.Name,
+		Ki:          domain.NewKi(a.Ki),
+		MaxKi:       domain.NewKi(a.MaxKi),
+		Race:        a.Race,
+		Gender:      a.Gender,
+		Description: a.Description,
+		ImageURL:    a.Image,
+		Affiliation: a.Affiliation,
+		DeletedAt:   a.DeletedAt,
+	}
+	if a.OriginPlanet != nil {
+		character.OriginPlanet = a.OriginPlanet.toDomain()
+	}
+	for _, transformation := range a.Transformations {
+		character.Transformations = append(character.Transformations, domain.Transformation{
+			ID:        transformation.ID.String(),
+			Name:      transformation.Name,
+			ImageURL:  transformation.Image,
+			Ki:        domain.NewKi(transformation.Ki),
+			DeletedAt: transformation.DeletedAt,
+		})
+	}
+	return character
+}
+
+// apiPage is one page of a paginated upstream list.
+type apiPage[T any] struct {
+	Items []T `json:"items"`
+	apiPagination
+}
+
+type apiPagination struct {
+	Meta struct {
+		TotalPages  int `json:"totalPages"`
+		CurrentPage int `json:"currentPage"`
+	} `json:"meta"`
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+const (
+	// pageSize is requested from the upstream to keep the number of round trips low.
+	pageSize = 50
+	// maxPages guards against upstream pagination links that never end.
+	maxPages = 100
+)
+
 type dragonBallAPIClient struct {
-	httpClient *http.Client
-	logger     *slog.Logger
+	httpClient     *http.Client
+	logger         *slog.Logger
+	baseURL        string
+	userAgent      string
+	requestTimeout time.Duration
+	retryPolicy    RetryPolicy
+	breaker        *CircuitBreaker
+	metrics        *clientMetrics
+	inflight       singleflight.Group
 }
 
-func NewDragonBallAPIClient(logger *slog.Logger) *dragonBallAPIClient {
+func NewDragonBallAPIClient(logger *slog.Logger, opts Options) (*dragonBallAPIClient, error) {
+	opts = opts.withDefaults()
+	httpClient, err := opts.newHTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure Dragon Ball API HTTP client: %w", err)
+	}
 	return &dragonBallAPIClient{
-		httpClient: &http.Client{},
-		logger:     logger,
+		httpClient:     httpClient,
+		logger:         logger,
+		baseURL:        strings.TrimSuffix(opts.BaseURL, "/"),
+		userAgent:      opts.UserAgent,
+		requestTimeout: opts.RequestTimeout,
+		retryPolicy:    opts.RetryPolicy,
+		breaker:        NewCircuitBreaker(opts.CircuitBreaker),
+		metrics:        newClientMetrics(opts.Registerer),
+	}, nil
+}
+
+// get performs a GET against the upstream through the circuit breaker,
+// retrying network errors and transient statuses. Any other response, or the
+// last one once retries are exhausted, is returned for the caller to interpret.
+// label is the endpoint template used in metrics.
+func (c *dragonBallAPIClient) get(ctx context.Context, label, endpoint string) (*http.Response, error) {
+	if err := c.breaker.Allow(); err != nil {
+		c.logger.WarnContext(ctx, "Dragon Ball API circuit breaker is open, failing fast", slog.String("url", endpoint))
+		c.metrics.recordError(label, reasonCircuitOpen)
+		return nil, fmt.Errorf("%w: %w", domain.ErrUpstreamUnavailable, err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := c.attempt(ctx, label, endpoint, attempt)
+		c.metrics.observeAttempt(label, resp, err, time.Since(start))
+		if err == nil && !isRetryableStatus(resp.StatusCode) {
+			c.breaker.RecordSuccess()
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			// The caller gave up, this says nothing about the upstream health.
+			c.breaker.RecordIgnored()
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		if attempt >= c.retryPolicy.MaxRetries {
+			c.breaker.RecordFailure()
+			return resp, err
+		}
+
+		delay := c.retryPolicy.backoff(attempt)
+		if resp != nil {
+			if wait, ok := retryAfter(resp); ok {
+				delay = min(wait, c.retryPolicy.MaxDelay)
+			}
+			c.logger.WarnContext(ctx, "Dragon Ball API returned a transient status, retrying", slog.Int("status_code", resp.StatusCode), slog.Int("attempt", attempt+1), slog.Duration("delay", delay), slog.String("url", endpoint))
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		} else {
+			c.logger.WarnContext(ctx, "Dragon Ball API request failed, retrying", slog.String("error", err.Error()), slog.Int("attempt", attempt+1), slog.Duration("delay", delay), slog.String("url", endpoint))
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			c.breaker.RecordIgnored()
+			return nil, err
+		}
+	}
+}
+
+// attempt performs a single GET bounded by the per-request timeout, traced
+// by its own client span. The timeout is released and the span ended when the
+// response body is closed.
+func (c *dragonBallAPIClient) attempt(ctx context.Context, label, endpoint string, attempt int) (*http.Response, error) {
+	ctx, span := startRequestSpan(ctx, label, endpoint, attempt)
+	ctx, cancel := context.WithTimeout(ctx, c.requestTimeout)
+	release := func() {
+		cancel()
+		span.End()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		recordSpanError(span, err)
+		release()
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	// Propagate the trace to the upstream with a W3C traceparent header.
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		recordSpanError(span, err)
+		release()
+		return nil, err
+	}
+	recordResponse(span, resp)
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: release}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// CircuitState exposes the circuit breaker state for health checks.
+func (c *dragonBallAPIClient) CircuitState() CircuitState {
+	return c.breaker.State()
+}
+
+func (c *dragonBallAPIClient) FindCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
+	return c.coalesce(ctx, "name:"+strings.ToLower(name), func(ctx context.Context) (*domain.Character, error) {
+		return c.findCharacterByName(ctx, name)
+	})
+}
+
+func (c *dragonBallAPIClient) FindCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	return c.coalesce(ctx, "id:"+id, func(ctx context.Context) (*domain.Character, error) {
+		return c.findCharacterByID(ctx, id)
+	})
+}
+
+// coalesce runs fetch once for concurrent calls sharing key, every caller
+// getting its own copy of the result. The fetch is bounded by the request
+// timeout and retry policy rather than by the context of the first caller.
+func (c *dragonBallAPIClient) coalesce(ctx context.Context, key string, fetch func(context.Context) (*domain.Character, error)) (*domain.Character, error) {
+	results := c.inflight.DoChan(key, func() (any, error) {
+		return fetch(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		c.logger.WarnContext(ctx, "Gave up waiting for Dragon Ball API", slog.String("error", ctx.Err().Error()), slog.String("key", key))
+		return nil, fmt.Errorf("failed to make API request: %w", classifyRequestError(ctx.Err()))
+	case res := <-results:
+		if res.Shared {
+			c.logger.DebugContext(ctx, "Shared an in-flight Dragon Ball API lookup", slog.String("key", key))
+		}
+		character, _ := res.Val.(*domain.Character)
+		if res.Err != nil || character == nil {
+			return nil, res.Err
+		}
+		return character.Clone(), nil
 	}
 }
 
-func (c *dragonBallAPIClient) FindCharacterByName(name string) (*domain.Character, error) {
+func (c *dragonBallAPIClient) findCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
 	// The API does not directly support lookup by name.
-	// We need to fetch all characters and then filter. This is inefficient but dictated by the API.
-	c.logger.Info("Fetching all characters from external API to find by name", slog.String("target_name", name))
+	// We need to walk the paginated character list and filter. This is inefficient but dictated by the API.
+	c.logger.InfoContext(ctx, "Fetching characters from external API to find by name", slog.String("target_name", name))
+
+	pageURL := fmt.Sprintf("%s/characters?page=1&limit=%d", c.baseURL, pageSize)
+	for pages := 1; pageURL != ""; pages++ {
+		if pages > maxPages {
+			c.logger.ErrorContext(ctx, "Dragon Ball API pagination did not terminate", slog.Int("max_pages", maxPages))
+			return nil, fmt.Errorf("%w: pagination exceeded %d pages", domain.ErrUpstreamUnavailable, maxPages)
+		}
+
+		apiResponse, err := c.fetchCharactersPage(ctx, pageURL)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, apiChar := range apiResponse.Items {
+			if strings.EqualFold(apiChar.Name, name) {
+				c.logger.InfoContext(ctx, "Character found in external API by name", slog.String("character_name", name), slog.String("character_id", apiChar.ID.String()), slog.Int("page", pages)) // Convert json.Number to string
+				// Only the character itself has the full profile
+				return c.findCharacterByID(ctx, apiChar.ID.String())
+			}
+		}
+
+		pageURL, err = nextPageURL(pageURL, apiResponse.apiPagination)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "Failed to resolve next page of Dragon Ball API characters", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%w: invalid pagination link: %w", domain.ErrUpstreamUnavailable, err)
+		}
+	}
+
+	c.logger.InfoContext(ctx, "Character not found in external API by name", slog.String("character_name", name))
+	return nil, nil // Character not found
+}
 
-	resp, err := c.httpClient.Get(fmt.Sprintf("%s/characters", BaseURL))
+// fetchCharactersPage retrieves and decodes a single page of the character list.
+func (c *dragonBallAPIClient) fetchCharactersPage(ctx context.Context, pageURL string) (*apiPage[apiCharacter], error) {
+	return fetchPage[apiCharacter](ctx, c, endpointCharacters, pageURL)
+}
+
+// fetchPage retrieves and decodes a single page of the upstream list at label.
+func fetchPage[T any](ctx context.Context, c *dragonBallAPIClient, label, pageURL string) (*apiPage[T], error) {
+	resp, err := c.get(ctx, label, pageURL)
 	if err != nil {
-		c.logger.Error("Failed to make request to Dragon Ball API", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to make API request: %w", err)
+		c.logger.ErrorContext(ctx, "Failed to make request to Dragon Ball API", slog.String("error", err.Error()), slog.String("url", pageURL))
+		return nil, fmt.Errorf("failed to make API request: %w", classifyRequestError(err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		c.logger.Error("Dragon Ball API returned non-OK status", slog.Int("status_code", resp.StatusCode), slog.String("response_body", string(bodyBytes)))
-		return nil, fmt.Errorf("the Dragon Ball API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+		c.logger.ErrorContext(ctx, "Dragon Ball API returned non-OK status", slog.Int("status_code", resp.StatusCode), slog.String("response_body", string(bodyBytes)), slog.String("url", pageURL))
+		return nil, fmt.Errorf("%w: the Dragon Ball API returned status %d: %s", domain.ErrUpstreamUnavailable, resp.StatusCode, string(bodyBytes))
 	}
 
-	var apiResponse apiCharactersResponse
+	var apiResponse apiPage[T]
 	// Enable decoding numbers into json.Number
 	decoder := json.NewDecoder(resp.Body)
 	decoder.UseNumber() // Crucial for json.Number to work
 	if err := decoder.Decode(&apiResponse); err != nil {
-		c.logger.Error("Failed to decode Dragon Ball API response", slog.String("error", err.Error()))
-		return nil, fmt.Errorf("failed to decode API response: %w", err)
-	}
-
-	for _, apiChar := range apiResponse.Items {
-		if apiChar.Name == name {
-			c.logger.Info("Character found in external API by name", slog.String("character_name", name), slog.String("character_id", apiChar.ID.String())) // Convert json.Number to string
-			return &domain.Character{
-				ID:   apiChar.ID.String(),
-				Name: apiChar.Name,
-				Ki:   apiChar.Ki,
-				Race: apiChar.Race,
-			}, nil
+		c.logger.ErrorContext(ctx, "Failed to decode Dragon Ball API response", slog.String("error", err.Error()), slog.String("url", pageURL))
+		c.metrics.recordError(label, reasonDecode)
+		return nil, fmt.Errorf("%w: failed to decode API response: %w", domain.ErrUpstreamUnavailable, err)
+	}
+	return &apiResponse, nil
+}
+
+// nextPageURL returns the URL of the page after currentURL, or "" on the last
+// page. links.next is preferred; meta is used when the links are missing.
+// Relative links are resolved against the current page.
+func nextPageURL(currentURL string, page apiPagination) (string, error) {
+	current, err := url.Parse(currentURL)
+	if err != nil {
+		return "", err
+	}
+
+	if page.Links.Next != "" {
+		next, err := current.Parse(page.Links.Next)
+		if err != nil {
+			return "", err
+		}
+		if next.String() == current.String() {
+			return "", fmt.Errorf("next page link points to the current page")
 		}
+		return next.String(), nil
 	}
 
-	c.logger.Info("Character not found in external API by name", slog.String("character_name", name))
-	return nil, nil // Character not found
+	if page.Meta.CurrentPage > 0 && page.Meta.CurrentPage < page.Meta.TotalPages {
+		query := current.Query()
+		query.Set("page", strconv.Itoa(page.Meta.CurrentPage+1))
+		current.RawQuery = query.Encode()
+		return current.String(), nil
+	}
+	return "", nil
 }
 
-func (c *dragonBallAPIClient) FindCharacterByID(id string) (*domain.Character, error) {
-	c.logger.Info("Fetching character by ID from external API", slog.String("character_id", id))
+func (c *dragonBallAPIClient) findCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	c.logger.InfoContext(ctx, "Fetching character by ID from external API", slog.String("character_id", id))
 
-	resp, err := c.httpClient.Get(fmt.Sprintf("%s/characters/%s", BaseURL, id))
+	resp, err := c.get(ctx, endpointCharacter, fmt.Sprintf("%s/characters/%s", c.baseURL, url.PathEscape(id)))
 	if err != nil {
-		c.logger.Error("Failed to make request to Dragon Ball API", slog.String("error", err.Error()), slog.String("character_id", id))
-		return nil, fmt.Errorf("failed to make API request: %w", err)
+		c.logger.ErrorContext(ctx, "Failed to make request to Dragon Ball API", slog.String("error", err.Error()), slog.String("character_id", id))
+		return nil, fmt.Errorf("failed to make API request: %w", classifyRequestError(err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		c.logger.Warn("Character not found in external API by ID", slog.String("character_id", id))
+		c.logger.WarnContext(ctx, "Character not found in external API by ID", slog.String("character_id", id))
 		return nil, nil
 	}
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		c.logger.Error("Dragon Ball API returned non-OK status for ID lookup", slog.Int("status_code", resp.StatusCode), slog.String("response_body", string(bodyBytes)), slog.String("character_id", id))
-		return nil, fmt.Errorf("the Dragon Ball API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+		c.logger.ErrorContext(ctx, "Dragon Ball API returned non-OK status for ID lookup", slog.Int("status_code", resp.StatusCode), slog.String("response_body", string(bodyBytes)), slog.String("character_id", id))
+		return nil, fmt.Errorf("%w: the Dragon Ball API returned status %d: %s", domain.ErrUpstreamUnavailable, resp.StatusCode, string(bodyBytes))
 	}
 
 	var apiChar apiCharacter
@@ -104,15 +406,39 @@ func (c *dragonBallAPIClient) FindCharacterByID(id string) (*domain.Character, e
 	decoder := json.NewDecoder(resp.Body)
 	decoder.UseNumber() // Crucial for json.Number to work
 	if err := decoder.Decode(&apiChar); err != nil {
-		c.logger.Error("Failed to decode Dragon Ball API response for ID lookup", slog.String("error", err.Error()), slog.String("character_id", id))
-		return nil, fmt.Errorf("failed to decode API response: %w", err)
+		c.logger.ErrorContext(ctx, "Failed to decode Dragon Ball API response for ID lookup", slog.String("error", err.Error()), slog.String("character_id", id))
+		c.metrics.recordError(endpointCharacter, reasonDecode)
+		return nil, fmt.Errorf("%w: failed to decode API response: %w", domain.ErrUpstreamUnavailable, err)
 	}
 
-	c.logger.Info("Character found in external API by ID", slog.String("character_id", apiChar.ID.String()), slog.String("character_name", apiChar.Name))
-	return &domain.Character{
-		ID:   apiChar.ID.String(), // Convert json.Number to string
-		Name: apiChar.Name,
-		Ki:   apiChar.Ki,
-		Race: apiChar.Race,
-	}, nil
+	c.logger.InfoContext(ctx, "Character found in external API by ID", slog.String("character_id", apiChar.ID.String()), slog.String("character_name", apiChar.Name))
+	return apiChar.toDomain(), nil
+}
+
+// classifyRequestError maps a transport failure onto the domain upstream
+// errors, telling timeouts apart from any other unreachable upstream.
+func classifyRequestError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return fmt.Errorf("%w: %w", domain.ErrUpstreamTimeout, err)
+	}
+	return fmt.Errorf("%w: %w", domain.ErrUpstreamUnavailable, err)
+}
+
+// Close releases the idle upstream connections held by the client.
+func (c *dragonBallAPIClient) Close() {
+	c.httpClient.CloseIdleConnections()
+}
+
+func (c *dragonBallAPIClient) Name() string {
+	return "dragonball_api"
+}
+
+// Check reports the upstream as unavailable while the circuit breaker is
+// open. It does not call the upstream, so probes never add to its load.
+func (c *dragonBallAPIClient) Check(ctx context.Context) error {
+	if state := c.breaker.State(); state == CircuitOpen {
+		return fmt.Errorf("circuit breaker is %s", state)
+	}
+	return nil
 }
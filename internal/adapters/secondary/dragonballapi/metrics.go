@@ -0,0 +1,76 @@
+package dragonballapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Endpoint labels, the route templates of the upstream calls.
+const (
+	endpointCharacters = "/characters"
+	endpointCharacter  = "/characters/{id}"
+	endpointPlanets    = "/planets"
+	endpointPlanet     = "/planets/{id}"
+)
+
+// Error reasons recorded by the upstream error counter.
+const (
+	reasonTimeout     = "timeout"
+	reasonUnavailable = "unavailable"
+	reasonStatus      = "status"
+	reasonCircuitOpen = "circuit_open"
+	reasonDecode      = "decode"
+)
+
+type clientMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+func newClientMetrics(registerer prometheus.Registerer) *clientMetrics {
+	return &clientMetrics{
+		duration: metrics.Register(registerer, prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "upstream",
+			Name:      "request_duration_seconds",
+			Help:      "Dragon Ball API request latency per attempt, by endpoint and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "status"})),
+		errors: metrics.Register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "upstream",
+			Name:      "errors_total",
+			Help:      "Dragon Ball API errors by endpoint and reason.",
+		}, []string{"endpoint", "reason"})),
+	}
+}
+
+// observeAttempt records the latency of a single attempt and, when it failed,
+// why. A 404 on a single character is an answer, not an error.
+func (m *clientMetrics) observeAttempt(endpoint string, resp *http.Response, err error, elapsed time.Duration) {
+	if err != nil {
+		m.duration.WithLabelValues(endpoint, "error").Observe(elapsed.Seconds())
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			m.recordError(endpoint, reasonTimeout)
+		} else if !errors.Is(err, context.Canceled) {
+			m.recordError(endpoint, reasonUnavailable)
+		}
+		return
+	}
+	m.duration.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Observe(elapsed.Seconds())
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+		m.recordError(endpoint, reasonStatus)
+	}
+}
+
+func (m *clientMetrics) recordError(endpoint, reason string) {
+	m.errors.WithLabelValues(endpoint, reason).Inc()
+}
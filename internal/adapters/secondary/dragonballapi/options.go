@@ -0,0 +1,126 @@
+package dragonballapi
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	DefaultBaseURL        = "https://dragonball-api.com/api"
+	DefaultRequestTimeout = 10 * time.Second
+	DefaultUserAgent      = "backend.go.characters.api"
+	DefaultMaxIdleConns   = 10
+)
+
+// Options configures a Dragon Ball API client. Start from DefaultOptions and
+// override what is needed; empty fields fall back to their defaults, except
+// RetryPolicy.MaxRetries where zero disables retries.
+type Options struct {
+	BaseURL string
+	// RequestTimeout bounds every upstream attempt on top of the caller's deadline.
+	RequestTimeout time.Duration
+	UserAgent      string
+	MaxIdleConns   int
+	TLS            TLSOptions
+
+	// HTTPClient, when set, is used as is and the transport settings above
+	// are ignored. Transport, when set, replaces the default transport.
+	HTTPClient *http.Client
+	Transport  http.RoundTripper
+
+	RetryPolicy    RetryPolicy
+	CircuitBreaker CircuitBreakerSettings
+
+	// Registerer receives the upstream latency and error metrics. When nil
+	// they are still recorded but never exposed.
+	Registerer prometheus.Registerer
+}
+
+type TLSOptions struct {
+	// CAFile is a PEM bundle trusted in addition to the system roots.
+	CAFile             string
+	InsecureSkipVerify bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		BaseURL:        DefaultBaseURL,
+		RequestTimeout: DefaultRequestTimeout,
+		UserAgent:      DefaultUserAgent,
+		MaxIdleConns:   DefaultMaxIdleConns,
+		RetryPolicy:    DefaultRetryPolicy(),
+		CircuitBreaker: DefaultCircuitBreakerSettings(),
+	}
+}
+
+func (o Options) withDefaults() Options {
+	if o.BaseURL == "" {
+		o.BaseURL = DefaultBaseURL
+	}
+	if o.RequestTimeout <= 0 {
+		o.RequestTimeout = DefaultRequestTimeout
+	}
+	if o.UserAgent == "" {
+		o.UserAgent = DefaultUserAgent
+	}
+	if o.MaxIdleConns <= 0 {
+		o.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if o.RetryPolicy.BaseDelay <= 0 {
+		o.RetryPolicy.BaseDelay = DefaultRetryPolicy().BaseDelay
+	}
+	if o.RetryPolicy.MaxDelay <= 0 {
+		o.RetryPolicy.MaxDelay = DefaultRetryPolicy().MaxDelay
+	}
+	return o
+}
+
+// newHTTPClient builds the HTTP client described by the options.
+func (o Options) newHTTPClient() (*http.Client, error) {
+	if o.HTTPClient != nil {
+		return o.HTTPClient, nil
+	}
+	if o.Transport != nil {
+		return &http.Client{Transport: o.Transport}, nil
+	}
+
+	tlsConfig, err := o.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = o.MaxIdleConns
+	transport.MaxIdleConnsPerHost = o.MaxIdleConns
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
+
+func (o TLSOptions) config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify, // Opt-in, meant for local upstream stand-ins only
+	}
+	if o.CAFile == "" {
+		return config, nil
+	}
+
+	pem, err := os.ReadFile(o.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", o.CAFile)
+	}
+	config.RootCAs = roots
+	return config, nil
+}
@@ -0,0 +1,101 @@
+package dragonballapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/domain"
+)
+
+// ListPlanets walks the paginated planet list of the upstream.
+func (c *dragonBallAPIClient) ListPlanets(ctx context.Context) ([]*domain.Planet, error) {
+	c.logger.InfoContext(ctx, "Fetching planets from external API")
+
+	planets := []*domain.Planet{}
+	pageURL := fmt.Sprintf("%s/planets?page=1&limit=%d", c.baseURL, pageSize)
+	for pages := 1; pageURL != ""; pages++ {
+		if pages > maxPages {
+			c.logger.ErrorContext(ctx, "Dragon Ball API pagination did not terminate", slog.Int("max_pages", maxPages))
+			return nil, fmt.Errorf("%w: pagination exceeded %d pages", domain.ErrUpstreamUnavailable, maxPages)
+		}
+
+		apiResponse, err := fetchPage[apiPlanet](ctx, c, endpointPlanets, pageURL)
+		if err != nil {
+			return nil, err
+		}
+		for _, apiPlanet := range apiResponse.Items {
+			planets = append(planets, apiPlanet.toDomain())
+		}
+
+		pageURL, err = nextPageURL(pageURL, apiResponse.apiPagination)
+		if err != nil {
+			c.logger.ErrorContext(ctx, "Failed to resolve next page of Dragon Ball API planets", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%w: invalid pagination link: %w", domain.ErrUpstreamUnavailable, err)
+		}
+	}
+
+	c.logger.InfoContext(ctx, "Planets fetched from external API", slog.Int("count", len(planets)))
+	return planets, nil
+}
+
+// FindPlanetByID returns nil, nil when the upstream does not know id.
+func (c *dragonBallAPIClient) FindPlanetByID(ctx context.Context, id string) (*domain.Planet, error) {
+	apiPlanet, err := c.findPlanetByID(ctx, id)
+	if err != nil || apiPlanet == nil {
+		return nil, err
+	}
+	return apiPlanet.toDomain(), nil
+}
+
+// ListPlanetCharacterIDs returns the IDs of the characters the upstream lists
+// for the planet id, nil when it does not know the planet.
+func (c *dragonBallAPIClient) ListPlanetCharacterIDs(ctx context.Context, id string) ([]string, error) {
+	apiPlanet, err := c.findPlanetByID(ctx, id)
+	if err != nil || apiPlanet == nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(apiPlanet.Characters))
+	for _, character := range apiPlanet.Characters {
+		ids = append(ids, character.ID.String())
+	}
+	return ids, nil
+}
+
+func (c *dragonBallAPIClient) findPlanetByID(ctx context.Context, id string) (*apiPlanet, error) {
+	c.logger.InfoContext(ctx, "Fetching planet by ID from external API", slog.String("planet_id", id))
+
+	resp, err := c.get(ctx, endpointPlanet, fmt.Sprintf("%s/planets/%s", c.baseURL, url.PathEscape(id)))
+	if err != nil {
+		c.logger.ErrorContext(ctx, "Failed to make request to Dragon Ball API", slog.String("error", err.Error()), slog.String("planet_id", id))
+		return nil, fmt.Errorf("failed to make API request: %w", classifyRequestError(err))
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		c.logger.WarnContext(ctx, "Planet not found in external API by ID", slog.String("planet_id", id))
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		c.logger.ErrorContext(ctx, "Dragon Ball API returned non-OK status for planet lookup", slog.Int("status_code", resp.StatusCode), slog.String("response_body", string(bodyBytes)), slog.String("planet_id", id))
+		return nil, fmt.Errorf("%w: the Dragon Ball API returned status %d: %s", domain.ErrUpstreamUnavailable, resp.StatusCode, string(bodyBytes))
+	}
+
+	var apiPlanet apiPlanet
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&apiPlanet); err != nil {
+		c.logger.ErrorContext(ctx, "Failed to decode Dragon Ball API response for planet lookup", slog.String("error", err.Error()), slog.String("planet_id", id))
+		c.metrics.recordError(endpointPlanet, reasonDecode)
+		return nil, fmt.Errorf("%w: failed to decode API response: %w", domain.ErrUpstreamUnavailable, err)
+	}
+
+	c.logger.InfoContext(ctx, "Planet found in external API by ID", slog.String("planet_id", apiPlanet.ID.String()), slog.String("planet_name", apiPlanet.Name))
+	return &apiPlanet, nil
+}
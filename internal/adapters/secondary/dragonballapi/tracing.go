@@ -0,0 +1,49 @@
+package dragonballapi
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "backend.go.characters.api/internal/adapters/secondary/dragonballapi"
+
+// startRequestSpan starts a client span for one upstream attempt. Retries get
+// a span of their own, numbered by http.request.resend_count.
+func startRequestSpan(ctx context.Context, label, endpoint string, attempt int) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodGet,
+		semconv.URLFull(endpoint),
+		semconv.URLTemplate(label),
+	}
+	if parsed, err := url.Parse(endpoint); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(parsed.Hostname()))
+	}
+	if attempt > 0 {
+		attrs = append(attrs, semconv.HTTPRequestResendCount(attempt))
+	}
+	return otel.Tracer(tracerName).Start(ctx, http.MethodGet+" "+label,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// recordResponse records the response status on span, marking error statuses
+// other than 404 as failures.
+func recordResponse(span trace.Span, resp *http.Response) {
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode != http.StatusNotFound {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+}
+
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,224 @@
+package lrucache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/domain"
+	"backend.go.characters.api/internal/core/ports"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	DefaultMaxEntries = 1000
+	DefaultMaxBytes   = 64 << 20
+	DefaultTTL        = 5 * time.Minute
+)
+
+// Options configures the cache. Empty fields fall back to their defaults.
+type Options struct {
+	// MaxEntries bounds the number of cached characters, the least recently
+	// used one is evicted first.
+	MaxEntries int
+	// MaxBytes bounds the estimated memory held by the cached characters,
+	// evicting like MaxEntries. A character larger than MaxBytes on its own
+	// is not cached.
+	MaxBytes int64
+	// TTL bounds how long a character is served from memory before it is
+	// read from the wrapped repository again.
+	TTL time.Duration
+
+	// Registerer receives the cache metrics. When nil they are still recorded
+	// but never exposed.
+	Registerer prometheus.Registerer
+}
+
+type entry struct {
+	character *domain.Character
+	nameKey   string
+	expiresAt time.Time
+	// size is the estimated memory the entry holds, see entrySize.
+	size int64
+}
+
+// characterRepository is a ports.CharacterRepository keeping the characters
+// read from or written to the wrapped repository in an LRU, indexed by ID and
+// by normalized name. Listings always go to the wrapped repository.
+type characterRepository struct {
+	next       ports.CharacterRepository
+	logger     *slog.Logger
+	maxEntries int
+	maxBytes   int64
+	ttl        time.Duration
+	metrics    *cacheMetrics
+	now        func() time.Time
+
+	mu     sync.Mutex
+	lru    *list.List // of *entry, most recently used first
+	byID   map[string]*list.Element
+	byName map[string]*list.Element
+	bytes  int64 // sum of the entry sizes
+}
+
+func NewCharacterRepository(next ports.CharacterRepository, logger *slog.Logger, opts Options) *characterRepository {
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultMaxEntries
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	return &characterRepository{
+		next:       next,
+		logger:     logger,
+		maxEntries: opts.MaxEntries,
+		maxBytes:   opts.MaxBytes,
+		ttl:        opts.TTL,
+		metrics:    newCacheMetrics(opts.Registerer),
+		now:        time.Now,
+		lru:        list.New(),
+		byID:       make(map[string]*list.Element),
+		byName:     make(map[string]*list.Element),
+	}
+}
+
+// SaveCharacter writes through: the character is cached once the wrapped
+// repository stored it, and dropped if that failed since the stored state is
+// then unknown.
+func (r *characterRepository) SaveCharacter(ctx context.Context, character *domain.Character) error {
+	if err := r.next.SaveCharacter(ctx, character); err != nil {
+		r.mu.Lock()
+		if element, ok := r.byID[character.ID]; ok {
+			r.remove(element)
+		}
+		r.mu.Unlock()
+		return err
+	}
+	r.store(character)
+	return nil
+}
+
+func (r *characterRepository) FindCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
+	if character := r.lookup(r.byName, normalizeName(name), lookupName); character != nil {
+		r.logger.DebugContext(ctx, "Character served from memory cache", slog.String("character_name", name))
+		return character, nil
+	}
+	character, err := r.next.FindCharacterByName(ctx, name)
+	if err != nil || character == nil {
+		return character, err
+	}
+	r.store(character)
+	return character, nil
+}
+
+func (r *characterRepository) FindCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	if character := r.lookup(r.byID, id, lookupID); character != nil {
+		r.logger.DebugContext(ctx, "Character served from memory cache", slog.String("character_id", id))
+		return character, nil
+	}
+	character, err := r.next.FindCharacterByID(ctx, id)
+	if err != nil || character == nil {
+		return character, err
+	}
+	r.store(character)
+	return character, nil
+}
+
+func (r *characterRepository) ListCharacters(ctx context.Context, params domain.CharacterListParams) (*domain.CharacterPage, error) {
+	return r.next.ListCharacters(ctx, params)
+}
+
+// InvalidateCharacters drops the cached characters with the given IDs.
+func (r *characterRepository) InvalidateCharacters(ctx context.Context, ids []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		if element, ok := r.byID[id]; ok {
+			r.remove(element)
+			r.metrics.evictions.WithLabelValues(reasonInvalidated).Inc()
+		}
+	}
+	return nil
+}
+
+// lookup returns a copy of the live entry indexed under key, nil on a miss.
+// Callers own the copy, the cached character is never handed out.
+func (r *characterRepository) lookup(index map[string]*list.Element, key, label string) *domain.Character {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, ok := index[key]
+	if !ok {
+		r.metrics.recordLookup(label, resultMiss)
+		return nil
+	}
+	cached := element.Value.(*entry)
+	if !r.now().Before(cached.expiresAt) {
+		r.remove(element)
+		r.metrics.evictions.WithLabelValues(reasonExpired).Inc()
+		r.metrics.recordLookup(label, resultMiss)
+		return nil
+	}
+	r.lru.MoveToFront(element)
+	r.metrics.recordLookup(label, resultHit)
+	return cached.character.Clone()
+}
+
+// store caches a copy of character, replacing any entry for the same ID, and
+// evicts the least recently used entries beyond the entry or byte bound.
+func (r *characterRepository) store(character *domain.Character) {
+	cached := &entry{
+		character: character.Clone(),
+		nameKey:   normalizeName(character.Name),
+		expiresAt: r.now().Add(r.ttl),
+	}
+	// Serving metadata belongs to one response, not to the stored character
+	cached.character.Cache = nil
+	cached.size = entrySize(cached)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if element, ok := r.byID[character.ID]; ok {
+		r.remove(element)
+	}
+	if cached.size > r.maxBytes {
+		return
+	}
+	element := r.lru.PushFront(cached)
+	r.byID[character.ID] = element
+	r.byName[cached.nameKey] = element
+	r.bytes += cached.size
+
+	for r.lru.Len() > r.maxEntries || r.bytes > r.maxBytes {
+		r.remove(r.lru.Back())
+		r.metrics.evictions.WithLabelValues(reasonCapacity).Inc()
+	}
+	r.metrics.entries.Set(float64(r.lru.Len()))
+	r.metrics.bytes.Set(float64(r.bytes))
+}
+
+// remove drops element from the LRU and both indexes. The name index may
+// already point to a newer character with the same name, which is kept.
+func (r *characterRepository) remove(element *list.Element) {
+	cached := r.lru.Remove(element).(*entry)
+	r.bytes -= cached.size
+	delete(r.byID, cached.character.ID)
+	if r.byName[cached.nameKey] == element {
+		delete(r.byName, cached.nameKey)
+	}
+	r.metrics.entries.Set(float64(r.lru.Len()))
+	r.metrics.bytes.Set(float64(r.bytes))
+}
+
+// normalizeName matches the case-insensitive lookup of the wrapped repository.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -0,0 +1,64 @@
+package lrucache
+
+import (
+	"backend.go.characters.api/internal/adapters/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Lookup labels, the index a lookup went through.
+const (
+	lookupID   = "id"
+	lookupName = "name"
+)
+
+const (
+	resultHit  = "hit"
+	resultMiss = "miss"
+)
+
+// Eviction reasons.
+const (
+	reasonCapacity    = "capacity"
+	reasonExpired     = "expired"
+	reasonInvalidated = "invalidated"
+)
+
+type cacheMetrics struct {
+	lookups   *prometheus.CounterVec
+	evictions *prometheus.CounterVec
+	entries   prometheus.Gauge
+	bytes     prometheus.Gauge
+}
+
+func newCacheMetrics(registerer prometheus.Registerer) *cacheMetrics {
+	return &cacheMetrics{
+		lookups: metrics.Register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "repository_cache",
+			Name:      "lookups_total",
+			Help:      "In-memory character cache lookups by index (id, name) and result (hit, miss).",
+		}, []string{"lookup", "result"})),
+		evictions: metrics.Register(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "repository_cache",
+			Name:      "evictions_total",
+			Help:      "In-memory character cache evictions by reason (capacity, expired, invalidated).",
+		}, []string{"reason"})),
+		entries: metrics.Register(registerer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "repository_cache",
+			Name:      "entries",
+			Help:      "Characters currently held by the in-memory cache.",
+		})),
+		bytes: metrics.Register(registerer, prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metrics.Namespace,
+			Subsystem: "repository_cache",
+			Name:      "bytes",
+			Help:      "Estimated memory held by the characters in the in-memory cache.",
+		})),
+	}
+}
+
+func (m *cacheMetrics) recordLookup(lookup, result string) {
+	m.lookups.WithLabelValues(lookup, result).Inc()
+}
@@ -0,0 +1,33 @@
+package lrucache
+
+import "backend.go.characters.api/internal/core/domain"
+
+// Rough fixed costs, in bytes, of what entrySize cannot measure from the
+// strings: struct fields, pointers, list element and index map slots.
+const (
+	entryOverhead          = 512
+	planetOverhead         = 160
+	transformationOverhead = 96
+	// kiOverhead covers the parsed value of a Ki next to its text.
+	kiOverhead = 64
+)
+
+// entrySize estimates the memory held by cached. It only needs to grow with
+// the character, so the byte bound follows long descriptions and many
+// transformations rather than matching the heap exactly.
+func entrySize(cached *entry) int64 {
+	c := cached.character
+	size := entryOverhead + len(cached.nameKey) + len(c.ID) + len(c.Name) + len(c.Race) + len(c.Gender) +
+		len(c.Description) + len(c.ImageURL) + len(c.Affiliation) + kiSize(c.Ki) + kiSize(c.MaxKi)
+	if p := c.OriginPlanet; p != nil {
+		size += planetOverhead + len(p.ID) + len(p.Name) + len(p.Description) + len(p.ImageURL)
+	}
+	for _, t := range c.Transformations {
+		size += transformationOverhead + len(t.ID) + len(t.Name) + len(t.ImageURL) + kiSize(t.Ki)
+	}
+	return int64(size)
+}
+
+func kiSize(ki domain.Ki) int {
+	return kiOverhead + len(ki.String())
+}
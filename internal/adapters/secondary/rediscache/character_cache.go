@@ -0,0 +1,169 @@
+package rediscache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	DefaultTTL       = 10 * time.Minute
+	DefaultKeyPrefix = "characters-api"
+)
+
+// Options configures the cache. Empty fields fall back to their defaults.
+type Options struct {
+	// TTL bounds how long a character stays in Redis after it was written.
+	TTL time.Duration
+	// KeyPrefix namespaces the keys, so several deployments can share a
+	// Redis instance.
+	KeyPrefix string
+}
+
+// characterCache stores characters in Redis as JSON, once under their ID and
+// once under their normalized name, so both lookups take a single GET.
+type characterCache struct {
+	client    redis.UniversalClient
+	logger    *slog.Logger
+	ttl       time.Duration
+	keyPrefix string
+}
+
+func NewCharacterCache(client redis.UniversalClient, logger *slog.Logger, opts Options) *characterCache {
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+	if opts.KeyPrefix == "" {
+		opts.KeyPrefix = DefaultKeyPrefix
+	}
+	return &characterCache{
+		client:    client,
+		logger:    logger,
+		ttl:       opts.TTL,
+		keyPrefix: opts.KeyPrefix,
+	}
+}
+
+func (c *characterCache) GetCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
+	ctx, span := startCommandSpan(ctx, "GetCharacterByName", "GET")
+	defer span.End()
+
+	character, err := c.get(ctx, c.nameKey(name))
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to read character from Redis", slog.String("error", err.Error()), slog.String("character_name", name))
+		recordSpanError(span, err)
+		return nil, err
+	}
+	return character, nil
+}
+
+func (c *characterCache) GetCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	ctx, span := startCommandSpan(ctx, "GetCharacterByID", "GET")
+	defer span.End()
+
+	character, err := c.get(ctx, c.idKey(id))
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to read character from Redis", slog.String("error", err.Error()), slog.String("character_id", id))
+		recordSpanError(span, err)
+		return nil, err
+	}
+	return character, nil
+}
+
+// SetCharacter writes character under both keys in one transaction, so a
+// lookup by name never finds a version the lookup by ID does not.
+func (c *characterCache) SetCharacter(ctx context.Context, character *domain.Character) error {
+	ctx, span := startCommandSpan(ctx, "SetCharacter", "SET")
+	defer span.End()
+
+	// Serving metadata belongs to one response, not to the cached character
+	stored := *character
+	stored.Cache = nil
+	data, err := json.Marshal(stored)
+	if err != nil {
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to encode character for cache: %w", err)
+	}
+
+	_, err = c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.idKey(character.ID), data, c.ttl)
+		pipe.Set(ctx, c.nameKey(character.Name), data, c.ttl)
+		return nil
+	})
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to write character to Redis", slog.String("error", err.Error()), slog.String("character_id", character.ID))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to cache character: %w", err)
+	}
+	return nil
+}
+
+// InvalidateCharacters deletes the characters with the given IDs under both
+// keys. The name keys are those of the cached copies, read first.
+func (c *characterCache) InvalidateCharacters(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	ctx, span := startCommandSpan(ctx, "InvalidateCharacters", "DEL")
+	defer span.End()
+
+	keys := make([]string, 0, 2*len(ids))
+	for _, id := range ids {
+		keys = append(keys, c.idKey(id))
+	}
+	values, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		c.logger.WarnContext(ctx, "Failed to read characters to invalidate from Redis", slog.String("error", err.Error()), slog.Int("count", len(ids)))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to invalidate cached characters: %w", err)
+	}
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+		var character domain.Character
+		// An undecodable copy is only deleted under its ID
+		if err := json.Unmarshal([]byte(data), &character); err == nil {
+			keys = append(keys, c.nameKey(character.Name))
+		}
+	}
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		c.logger.WarnContext(ctx, "Failed to delete characters from Redis", slog.String("error", err.Error()), slog.Int("count", len(ids)))
+		recordSpanError(span, err)
+		return fmt.Errorf("failed to invalidate cached characters: %w", err)
+	}
+	return nil
+}
+
+func (c *characterCache) get(ctx context.Context, key string) (*domain.Character, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached character: %w", err)
+	}
+	var character domain.Character
+	if err := json.Unmarshal(data, &character); err != nil {
+		return nil, fmt.Errorf("failed to decode cached character: %w", err)
+	}
+	return &character, nil
+}
+
+func (c *characterCache) idKey(id string) string {
+	return c.keyPrefix + ":character:id:" + id
+}
+
+// nameKey matches the case-insensitive lookup of the repository.
+func (c *characterCache) nameKey(name string) string {
+	return c.keyPrefix + ":character:name:" + strings.ToLower(strings.TrimSpace(name))
+}
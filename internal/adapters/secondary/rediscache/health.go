@@ -0,0 +1,27 @@
+package rediscache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type redisHealthChecker struct {
+	client redis.UniversalClient
+}
+
+func NewRedisHealthChecker(client redis.UniversalClient) *redisHealthChecker {
+	return &redisHealthChecker{client: client}
+}
+
+func (h *redisHealthChecker) Name() string {
+	return "redis"
+}
+
+func (h *redisHealthChecker) Check(ctx context.Context) error {
+	if err := h.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("failed to ping redis: %w", err)
+	}
+	return nil
+}
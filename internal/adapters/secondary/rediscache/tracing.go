@@ -0,0 +1,30 @@
+package rediscache
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "backend.go.characters.api/internal/adapters/secondary/rediscache"
+
+// startCommandSpan starts a client span for a Redis command, named after the
+// command as the database semantic conventions ask.
+func startCommandSpan(ctx context.Context, method, command string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, command,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBOperationName(command),
+			semconv.CodeFunction(method),
+		),
+	)
+}
+
+func recordSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
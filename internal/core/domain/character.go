@@ -3,14 +3,85 @@ package domain
 import "time"
 
 type Character struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Ki        string    `json:"ki"`
-	Race      string    `json:"race"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Ki          Ki     `json:"ki"`
+	MaxKi       Ki     `json:"max_ki"`
+	Race        string `json:"race"`
+	Gender      string `json:"gender"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	Affiliation string `json:"affiliation"`
+	// DeletedAt is set when the upstream marks the character as deleted.
+	DeletedAt       *time.Time       `json:"deleted_at,omitempty"`
+	OriginPlanet    *Planet          `json:"origin_planet,omitempty"`
+	Transformations []Transformation `json:"transformations,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	// Cache is set on characters served by a lookup, never stored.
+	Cache *CacheInfo `json:"cache,omitempty"`
 }
 
+type Transformation struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	ImageURL  string     `json:"image_url"`
+	Ki        Ki         `json:"ki"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// Clone returns a copy of c that shares nothing mutable with it, so caches
+// can hand characters out without exposing their own.
+func (c *Character) Clone() *Character {
+	clone := *c
+	clone.DeletedAt = cloneTime(c.DeletedAt)
+	if c.OriginPlanet != nil {
+		clone.OriginPlanet = c.OriginPlanet.Clone()
+	}
+	if c.Transformations != nil {
+		clone.Transformations = make([]Transformation, len(c.Transformations))
+		for i, transformation := range c.Transformations {
+			transformation.DeletedAt = cloneTime(transformation.DeletedAt)
+			clone.Transformations[i] = transformation
+		}
+	}
+	if c.Cache != nil {
+		cache := *c.Cache
+		clone.Cache = &cache
+	}
+	return &clone
+}
+
+func cloneTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	clone := *t
+	return &clone
+}
+
+// MaxCharacterNameLength is the longest name, in characters, the name columns hold.
+const MaxCharacterNameLength = 255
+
 type NewCharacterRequest struct {
 	Name string `json:"name" binding:"required"`
 }
+
+// LookupOutcome tells where a character lookup was resolved.
+type LookupOutcome string
+
+const (
+	LookupLocalHit      LookupOutcome = "local_hit"
+	LookupCacheHit      LookupOutcome = "cache_hit"
+	LookupUpstreamFetch LookupOutcome = "upstream_fetch"
+	LookupNotFound      LookupOutcome = "not_found"
+	// LookupKnownUnknown is a name recorded as unknown to the upstream,
+	// answered as not found without asking it again.
+	LookupKnownUnknown LookupOutcome = "known_unknown"
+	// LookupStaleHit is a local hit served while a background refresh runs.
+	LookupStaleHit LookupOutcome = "stale_hit"
+	// LookupRefreshed is a local hit past its max age, refreshed before being served.
+	LookupRefreshed LookupOutcome = "refreshed"
+	// LookupCoalesced is a lookup that joined an identical one in flight.
+	LookupCoalesced LookupOutcome = "coalesced"
+)
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DefaultCharacterPageSize = 20
+	MaxCharacterPageSize     = 100
+)
+
+type CharacterSortField string
+
+const (
+	CharacterSortByName      CharacterSortField = "name"
+	CharacterSortByCreatedAt CharacterSortField = "created_at"
+	CharacterSortByUpdatedAt CharacterSortField = "updated_at"
+	// CharacterSortByKi orders by the value of the power level, unknown
+	// power levels first.
+	CharacterSortByKi CharacterSortField = "ki"
+)
+
+// CharacterListParams describes a page request over the stored characters.
+// Cursor is an opaque token previously returned as CharacterPage.NextCursor.
+type CharacterListParams struct {
+	Race       string
+	NamePrefix string
+	// MinKi and MaxKi bound the power level, both inclusive. Characters whose
+	// power level is unknown are excluded by either bound.
+	MinKi *Ki
+	MaxKi *Ki
+	// OriginPlanetID only keeps the characters originating from that planet.
+	OriginPlanetID string
+	SortBy         CharacterSortField
+	Descending     bool
+	Limit          int
+	Cursor         string
+}
+
+type CharacterPage struct {
+	Items      []*Character `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// ParseCharacterSort parses a sort expression such as "name" or "-updated_at",
+// where a leading "-" requests descending order.
+func ParseCharacterSort(sort string) (CharacterSortField, bool, error) {
+	descending := strings.HasPrefix(sort, "-")
+	field := CharacterSortField(strings.TrimPrefix(sort, "-"))
+	switch field {
+	case "":
+		return CharacterSortByName, descending, nil
+	case CharacterSortByName, CharacterSortByCreatedAt, CharacterSortByUpdatedAt, CharacterSortByKi:
+		return field, descending, nil
+	}
+	return "", false, NewValidationError("sort", fmt.Sprintf("unknown field '%s', must be one of name, created_at, updated_at, ki", field))
+}
+
+// Normalize applies defaults to unset fields and validates the rest.
+func (p *CharacterListParams) Normalize() error {
+	if p.SortBy == "" {
+		p.SortBy = CharacterSortByName
+	}
+	switch p.SortBy {
+	case CharacterSortByName, CharacterSortByCreatedAt, CharacterSortByUpdatedAt, CharacterSortByKi:
+	default:
+		return NewValidationError("sort", fmt.Sprintf("unknown field '%s', must be one of name, created_at, updated_at, ki", p.SortBy))
+	}
+	if p.MinKi != nil && p.MaxKi != nil && p.MinKi.Cmp(*p.MaxKi) > 0 {
+		return NewValidationError("min_ki", "must not be greater than max_ki")
+	}
+
+	if p.Limit == 0 {
+		p.Limit = DefaultCharacterPageSize
+	}
+	if p.Limit < 0 || p.Limit > MaxCharacterPageSize {
+		return NewValidationError("limit", fmt.Sprintf("must be between 1 and %d", MaxCharacterPageSize))
+	}
+	return nil
+}
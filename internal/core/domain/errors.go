@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors shared by the core and its adapters. Services and adapters
+// wrap them with context, callers match them with errors.Is.
+var (
+	ErrNotFound            = errors.New("not found")
+	ErrValidation          = errors.New("validation failed")
+	ErrConflict            = errors.New("conflict")
+	ErrUpstreamUnavailable = errors.New("upstream API unavailable")
+	ErrUpstreamTimeout     = errors.New("upstream API timed out")
+)
+
+// ValidationError reports an invalid input field. It matches ErrValidation.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func NewValidationError(field, message string) *ValidationError {
+	return &ValidationError{Field: field, Message: message}
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Field, e.Message)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
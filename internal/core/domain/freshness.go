@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// Freshness tells whether a locally stored character can be served as is.
+type Freshness int
+
+const (
+	// Fresh characters are served from the local store.
+	Fresh Freshness = iota
+	// Stale characters are served while a refresh runs in the background.
+	Stale
+	// Expired characters are refreshed before being served.
+	Expired
+)
+
+// FreshnessPolicy decides when a locally stored character is refreshed from
+// the upstream, based on when it was last updated, and how long a name the
+// upstream does not know is answered as not found without asking it again.
+// A zero duration disables the matching check.
+type FreshnessPolicy struct {
+	TTL         time.Duration
+	MaxAge      time.Duration
+	NotFoundTTL time.Duration
+}
+
+func (p FreshnessPolicy) Classify(updatedAt, now time.Time) Freshness {
+	age := now.Sub(updatedAt)
+	switch {
+	case p.MaxAge > 0 && age >= p.MaxAge:
+		return Expired
+	case p.TTL > 0 && age >= p.TTL:
+		return Stale
+	default:
+		return Fresh
+	}
+}
+
+// CacheSource tells where a character served by the service came from.
+type CacheSource string
+
+const (
+	CacheSourceLocal    CacheSource = "local"
+	CacheSourceCache    CacheSource = "cache"
+	CacheSourceUpstream CacheSource = "upstream"
+)
+
+// CacheInfo describes how old the character data served is.
+type CacheInfo struct {
+	Source     CacheSource `json:"source"`
+	AgeSeconds int64       `json:"age_seconds"`
+	Stale      bool        `json:"stale"`
+}
@@ -0,0 +1,235 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// kiScales are the powers of ten named by the scale words the upstream uses.
+// A googolplex is 10^googol, which only fits as an exponent.
+var kiScales = map[string]*big.Int{
+	"thousand":          big.NewInt(3),
+	"million":           big.NewInt(6),
+	"billion":           big.NewInt(9),
+	"trillion":          big.NewInt(12),
+	"quadrillion":       big.NewInt(15),
+	"quintillion":       big.NewInt(18),
+	"sextillion":        big.NewInt(21),
+	"septillion":        big.NewInt(24),
+	"octillion":         big.NewInt(27),
+	"nonillion":         big.NewInt(30),
+	"decillion":         big.NewInt(33),
+	"undecillion":       big.NewInt(36),
+	"duodecillion":      big.NewInt(39),
+	"tredecillion":      big.NewInt(42),
+	"quattuordecillion": big.NewInt(45),
+	"quindecillion":     big.NewInt(48),
+	"sexdecillion":      big.NewInt(51),
+	"septendecillion":   big.NewInt(54),
+	"octodecillion":     big.NewInt(57),
+	"novemdecillion":    big.NewInt(60),
+	"vigintillion":      big.NewInt(63),
+	"googol":            big.NewInt(100),
+	"googolplex":        new(big.Int).Exp(big.NewInt(10), big.NewInt(100), nil),
+}
+
+var (
+	kiPattern = regexp.MustCompile(`^([0-9][0-9.,]*)\s*([A-Za-z]*)$`)
+	// Groups of three digits separated by the same dot or comma, e.g. 60.000.000
+	kiDotGroups   = regexp.MustCompile(`^[0-9]{1,3}(\.[0-9]{3})+$`)
+	kiCommaGroups = regexp.MustCompile(`^[0-9]{1,3}(,[0-9]{3})+$`)
+	// Grouped digits followed by a fraction in the other separator, e.g. 1,234.5
+	kiDotGroupsCommaFraction = regexp.MustCompile(`^[0-9]{1,3}(\.[0-9]{3})*,[0-9]+$`)
+	kiCommaGroupsDotFraction = regexp.MustCompile(`^[0-9]{1,3}(,[0-9]{3})*\.[0-9]+$`)
+	kiFraction               = regexp.MustCompile(`^[0-9]+[.,][0-9]+$`)
+	kiDigits                 = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// Ki is a power level as the upstream writes it, such as "60.000.000",
+// "24 Billion" or "90 Septillion", along with its exact value. The value is
+// kept as coefficient * 10^exponent, so even googolplex amounts are exact.
+// A Ki whose text is not a recognized amount, such as "unknown", has no value.
+type Ki struct {
+	text        string
+	coefficient *big.Int
+	exponent    *big.Int
+}
+
+// NewKi keeps text and parses its value when it is a recognized amount.
+func NewKi(text string) Ki {
+	ki, err := ParseKi(text)
+	if err != nil {
+		return Ki{text: text}
+	}
+	return ki
+}
+
+// ParseKi parses a power level written with dot or comma grouped digits, an
+// optional fraction and an optional scale word from thousand to googolplex.
+// Three digit groups are read as grouping, "1.500 Billion" is 1500 billions,
+// while "2.5 Billion" is two and a half billions.
+func ParseKi(text string) (Ki, error) {
+	match := kiPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return Ki{}, fmt.Errorf("invalid ki %q: expected digits optionally followed by a scale word", text)
+	}
+	number, word := match[1], strings.ToLower(match[2])
+
+	exponent := new(big.Int)
+	if word != "" {
+		scale, ok := kiScales[word]
+		if !ok {
+			return Ki{}, fmt.Errorf("invalid ki %q: unknown scale %q", text, match[2])
+		}
+		exponent.Set(scale)
+	}
+
+	var digits string
+	switch {
+	case kiDigits.MatchString(number):
+		digits = number
+	case kiDotGroups.MatchString(number), kiCommaGroups.MatchString(number):
+		digits = strings.NewReplacer(".", "", ",", "").Replace(number)
+	case kiDotGroupsCommaFraction.MatchString(number):
+		digits, exponent = kiWithFraction(strings.ReplaceAll(number, ".", ""), ",", exponent)
+	case kiCommaGroupsDotFraction.MatchString(number), kiFraction.MatchString(number):
+		separator := "."
+		if !strings.Contains(number, ".") {
+			separator = ","
+		}
+		digits, exponent = kiWithFraction(strings.ReplaceAll(number, oppositeSeparator(separator), ""), separator, exponent)
+	default:
+		return Ki{}, fmt.Errorf("invalid ki %q: malformed number %q", text, number)
+	}
+
+	coefficient, _ := new(big.Int).SetString(digits, 10)
+	ki := Ki{text: text, coefficient: coefficient, exponent: exponent}
+	ki.normalize()
+	if ki.coefficient.Sign() != 0 && ki.Magnitude().Sign() < 0 {
+		return Ki{}, fmt.Errorf("invalid ki %q: must be zero or at least 1", text)
+	}
+	return ki, nil
+}
+
+// kiWithFraction drops the decimal separator from number, moving the
+// exponent down by the number of fraction digits.
+func kiWithFraction(number, separator string, exponent *big.Int) (string, *big.Int) {
+	integer, fraction, _ := strings.Cut(number, separator)
+	return integer + fraction, exponent.Sub(exponent, big.NewInt(int64(len(fraction))))
+}
+
+func oppositeSeparator(separator string) string {
+	if separator == "." {
+		return ","
+	}
+	return "."
+}
+
+// normalize strips the trailing zeros of the coefficient into the exponent,
+// so equal values have equal representations.
+func (k *Ki) normalize() {
+	if k.coefficient.Sign() == 0 {
+		k.exponent.SetInt64(0)
+		return
+	}
+	ten := big.NewInt(10)
+	quotient, remainder := new(big.Int), new(big.Int)
+	for {
+		quotient.QuoRem(k.coefficient, ten, remainder)
+		if remainder.Sign() != 0 {
+			return
+		}
+		k.coefficient.Set(quotient)
+		k.exponent.Add(k.exponent, big.NewInt(1))
+	}
+}
+
+// String returns the power level as originally written.
+func (k Ki) String() string {
+	return k.text
+}
+
+// Known reports whether the text is a recognized amount with a value.
+func (k Ki) Known() bool {
+	return k.coefficient != nil
+}
+
+// IsZero reports a known amount of zero.
+func (k Ki) IsZero() bool {
+	return k.Known() && k.coefficient.Sign() == 0
+}
+
+// Magnitude returns the power of ten of the leading digit, 7 for 60.000.000,
+// nil when the value is unknown or zero.
+func (k Ki) Magnitude() *big.Int {
+	if !k.Known() || k.IsZero() {
+		return nil
+	}
+	digits := int64(len(k.coefficient.String()))
+	return new(big.Int).Add(k.exponent, big.NewInt(digits-1))
+}
+
+// Significand returns the value scaled between 1 and 10 as a decimal string,
+// "6" for 60.000.000 and "2.45" for 24.5 Billion, empty when the value is
+// unknown or zero. Values compare as their (Magnitude, Significand) pairs.
+func (k Ki) Significand() string {
+	if !k.Known() || k.IsZero() {
+		return ""
+	}
+	digits := k.coefficient.String()
+	if len(digits) == 1 {
+		return digits
+	}
+	return digits[:1] + "." + digits[1:]
+}
+
+// Cmp compares the values of k and other, returning -1, 0 or 1. Unknown
+// values sort before every known one, zero before every positive one.
+func (k Ki) Cmp(other Ki) int {
+	switch {
+	case !k.Known() || !other.Known():
+		return compareBools(k.Known(), other.Known())
+	case k.IsZero() || other.IsZero():
+		return compareBools(!k.IsZero(), !other.IsZero())
+	}
+	if c := k.Magnitude().Cmp(other.Magnitude()); c != 0 {
+		return c
+	}
+	// Same magnitude, the coefficients compare digit by digit
+	left, right := k.coefficient.String(), other.coefficient.String()
+	for len(left) < len(right) {
+		left += "0"
+	}
+	for len(right) < len(left) {
+		right += "0"
+	}
+	return strings.Compare(left, right)
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+// MarshalJSON writes the power level as originally written.
+func (k Ki) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.text)
+}
+
+func (k *Ki) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*k = NewKi(text)
+	return nil
+}
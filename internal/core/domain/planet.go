@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Planet is a planet of the Dragon Ball API, the origin of its characters.
+type Planet struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	IsDestroyed bool   `json:"is_destroyed"`
+	Description string `json:"description"`
+	ImageURL    string `json:"image_url"`
+	// DeletedAt is set when the upstream marks the planet as deleted.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	// CharactersImportedAt is when the characters of the planet were last
+	// imported from the upstream, nil before the first import.
+	CharactersImportedAt *time.Time `json:"-"`
+	// Cache is set on planets served by a lookup, never stored.
+	Cache *CacheInfo `json:"cache,omitempty"`
+}
+
+// PlanetList is the planet list imported from the upstream.
+type PlanetList struct {
+	Planets []*Planet
+	// ImportedAt is when the list was last imported, zero before the first import.
+	ImportedAt time.Time
+}
+
+// Clone returns a copy of p that shares nothing mutable with it.
+func (p *Planet) Clone() *Planet {
+	clone := *p
+	clone.DeletedAt = cloneTime(p.DeletedAt)
+	clone.CharactersImportedAt = cloneTime(p.CharactersImportedAt)
+	if p.Cache != nil {
+		cache := *p.Cache
+		clone.Cache = &cache
+	}
+	return &clone
+}
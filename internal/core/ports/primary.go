@@ -1,7 +1,34 @@
 package ports
 
-import "backend.go.characters.api/internal/core/domain"
+import (
+	"context"
+
+	"backend.go.characters.api/internal/core/domain"
+)
 
 type CharacterService interface {
-	CreateCharacter(characterName string) (*domain.Character, error)
+	// CreateCharacter returns the stored character with the given name,
+	// importing it from the external API first when needed. created reports
+	// whether the character was newly imported rather than already stored.
+	CreateCharacter(ctx context.Context, characterName string) (character *domain.Character, created bool, err error)
+	GetCharacter(ctx context.Context, id string) (*domain.Character, error)
+	ListCharacters(ctx context.Context, params domain.CharacterListParams) (*domain.CharacterPage, error)
+	// PurgeUnknownNames forgets the names recorded as unknown to the external
+	// API and returns how many there were.
+	PurgeUnknownNames(ctx context.Context) (int64, error)
+	// Close waits until ctx ends for the refreshes running in the background.
+	Close(ctx context.Context) error
+}
+
+type PlanetService interface {
+	// ListPlanets returns every planet, importing the list from the external
+	// API the first time.
+	ListPlanets(ctx context.Context) ([]*domain.Planet, error)
+	GetPlanet(ctx context.Context, id string) (*domain.Planet, error)
+	// ListPlanetCharacters pages over the stored characters originating from
+	// the planet id, which is looked up first. The characters the external
+	// API lists for the planet are imported the first time.
+	ListPlanetCharacters(ctx context.Context, id string, params domain.CharacterListParams) (*domain.CharacterPage, error)
+	// Close waits until ctx ends for the imports and refreshes running in the background.
+	Close(ctx context.Context) error
 }
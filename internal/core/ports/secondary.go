@@ -1,13 +1,80 @@
 package ports
 
-import "backend.go.characters.api/internal/core/domain"
+import (
+	"context"
+	"time"
+
+	"backend.go.characters.api/internal/core/domain"
+)
 
 type CharacterRepository interface {
-	SaveCharacter(character *domain.Character) error
-	FindCharacterByName(name string) (*domain.Character, error)
+	SaveCharacter(ctx context.Context, character *domain.Character) error
+	FindCharacterByName(ctx context.Context, name string) (*domain.Character, error)
+	FindCharacterByID(ctx context.Context, id string) (*domain.Character, error)
+	ListCharacters(ctx context.Context, params domain.CharacterListParams) (*domain.CharacterPage, error)
 }
 
 type DragonBallAPIClient interface {
-	FindCharacterByName(name string) (*domain.Character, error)
-	FindCharacterByID(id string) (*domain.Character, error)
+	FindCharacterByName(ctx context.Context, name string) (*domain.Character, error)
+	FindCharacterByID(ctx context.Context, id string) (*domain.Character, error)
+	ListPlanets(ctx context.Context) ([]*domain.Planet, error)
+	FindPlanetByID(ctx context.Context, id string) (*domain.Planet, error)
+	// ListPlanetCharacterIDs returns nil, nil when the upstream does not know the planet.
+	ListPlanetCharacterIDs(ctx context.Context, id string) ([]string, error)
+}
+
+// PlanetRepository stores the planets. Planets saved along with a character
+// are only listed once the planet list was imported with SavePlanetList.
+type PlanetRepository interface {
+	SavePlanet(ctx context.Context, planet *domain.Planet) error
+	// SavePlanetList saves the full planet list of the external API.
+	SavePlanetList(ctx context.Context, planets []*domain.Planet) error
+	FindPlanetByID(ctx context.Context, id string) (*domain.Planet, error)
+	// ListPlanets returns the planets of the imported list and when it was
+	// imported, with a zero ImportedAt until one was saved.
+	ListPlanets(ctx context.Context) (*domain.PlanetList, error)
+	// LinkPlanetCharacters sets the origin planet of the stored characters
+	// among characterIDs that have none to the planet id, and returns the IDs
+	// of those stored. Cached copies of these characters are not updated,
+	// callers invalidate them.
+	LinkPlanetCharacters(ctx context.Context, id string, characterIDs []string) ([]string, error)
+	// MarkPlanetCharactersImported records that the characters of the planet
+	// id were imported, setting its CharactersImportedAt.
+	MarkPlanetCharactersImported(ctx context.Context, id string) error
+}
+
+// CharacterCache is a cache of characters shared by every replica, looked up
+// before the repository. Lookups return nil, nil on a miss.
+type CharacterCache interface {
+	CharacterInvalidator
+	GetCharacterByName(ctx context.Context, name string) (*domain.Character, error)
+	GetCharacterByID(ctx context.Context, id string) (*domain.Character, error)
+	SetCharacter(ctx context.Context, character *domain.Character) error
+}
+
+// CharacterInvalidator drops cached copies of characters changed in the
+// repository behind the cache, so their next lookup reads them from there.
+type CharacterInvalidator interface {
+	InvalidateCharacters(ctx context.Context, ids []string) error
+}
+
+// UnknownNameRepository remembers the names the external API does not know,
+// so repeated lookups of a typo do not reach it. Names are stored as given,
+// callers normalize them.
+type UnknownNameRepository interface {
+	IsUnknownName(ctx context.Context, name string) (bool, error)
+	SaveUnknownName(ctx context.Context, name string, ttl time.Duration) error
+	// PurgeUnknownNames forgets every recorded name and returns how many there were.
+	PurgeUnknownNames(ctx context.Context) (int64, error)
+}
+
+// HealthChecker probes a dependency the service relies on.
+type HealthChecker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+// CharacterMetrics records how character lookups are resolved.
+type CharacterMetrics interface {
+	RecordLookup(operation string, outcome domain.LookupOutcome)
 }
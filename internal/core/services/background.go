@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundTasks tracks the work requests start without waiting for it, so
+// shutdown can wait for it before closing what it uses.
+type backgroundTasks struct {
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// Go runs task in its own goroutine, unless Close was called, and reports
+// whether it did.
+func (b *backgroundTasks) Go(task func()) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return false
+	}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		task()
+	}()
+	return true
+}
+
+// Close stops new tasks from starting and waits for the running ones until
+// ctx ends, returning ctx.Err() if they are still running then.
+func (b *backgroundTasks) Close(ctx context.Context) error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
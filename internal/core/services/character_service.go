@@ -1,67 +1,407 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"log/slog"
 
 	"backend.go.characters.api/internal/core/domain"
 	"backend.go.characters.api/internal/core/ports"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/singleflight"
 )
 
 type characterService struct {
 	characterRepository ports.CharacterRepository
 	dragonBallAPIClient ports.DragonBallAPIClient
+	characterCache      ports.CharacterCache
+	unknownNames        ports.UnknownNameRepository
+	metrics             ports.CharacterMetrics
+	freshness           domain.FreshnessPolicy
 	logger              *slog.Logger
+	createGroup         singleflight.Group
+	refreshGroup        singleflight.Group
+	background          backgroundTasks
 }
 
+const (
+	operationCreate = "create"
+	operationGet    = "get"
+)
+
+// backgroundRefreshTimeout bounds the refresh of a stale character, which no
+// request waits for.
+const backgroundRefreshTimeout = 30 * time.Second
+
+// NewCharacterService builds the service. characterCache and unknownNames are
+// optional: without a cache every lookup starts at the repository, without
+// unknownNames every unknown name is looked up upstream again.
 func NewCharacterService(
 	characterRepository ports.CharacterRepository,
 	dragonBallAPIClient ports.DragonBallAPIClient,
+	characterCache ports.CharacterCache,
+	unknownNames ports.UnknownNameRepository,
+	metrics ports.CharacterMetrics,
+	freshness domain.FreshnessPolicy,
 	logger *slog.Logger,
 ) ports.CharacterService {
 	return &characterService{
 		characterRepository: characterRepository,
 		dragonBallAPIClient: dragonBallAPIClient,
+		characterCache:      characterCache,
+		unknownNames:        unknownNames,
+		metrics:             metrics,
+		freshness:           freshness,
 		logger:              logger,
 	}
 }
 
-func (s *characterService) CreateCharacter(characterName string) (*domain.Character, error) {
-	s.logger.Info("Attempting to create or retrieve character", slog.String("character_name", characterName))
+func (s *characterService) CreateCharacter(ctx context.Context, characterName string) (character *domain.Character, created bool, err error) {
+	ctx, span := startSpan(ctx, "characterService.CreateCharacter", attribute.String("character.name", characterName))
+	defer func() { endSpan(span, err) }()
+
+	s.logger.InfoContext(ctx, "Attempting to create or retrieve character", slog.String("character_name", characterName))
+
+	characterName = strings.TrimSpace(characterName)
+	if characterName == "" {
+		s.logger.WarnContext(ctx, "Rejected blank character name")
+		return nil, false, domain.NewValidationError("name", "must not be blank")
+	}
+	// No character has such a name, and it would not fit the unknown names table
+	if utf8.RuneCountInString(characterName) > domain.MaxCharacterNameLength {
+		s.logger.WarnContext(ctx, "Rejected overlong character name", slog.Int("length", utf8.RuneCountInString(characterName)))
+		return nil, false, domain.NewValidationError("name", fmt.Sprintf("must be at most %d characters", domain.MaxCharacterNameLength))
+	}
 
-	// 1. Check if character exists in local database
-	existingCharacter, err := s.characterRepository.FindCharacterByName(characterName)
+	// Concurrent calls for the same name share one lookup, so a burst of
+	// requests for an unknown character costs a single upstream fetch and a
+	// single write.
+	result, leader, err := coalesce(ctx, &s.createGroup, normalizeName(characterName), func(ctx context.Context) (createResult, error) {
+		character, created, err := s.createCharacter(ctx, characterName)
+		return createResult{character: character, created: created}, err
+	})
+	if ctxErr := ctx.Err(); ctxErr != nil && err == ctxErr {
+		s.logger.WarnContext(ctx, "Gave up waiting for character lookup", slog.String("error", ctxErr.Error()), slog.String("character_name", characterName))
+		return nil, false, err
+	}
+	if !leader {
+		s.logger.InfoContext(ctx, "Joined in-flight character lookup", slog.String("character_name", characterName))
+		s.recordLookup(ctx, operationCreate, domain.LookupCoalesced)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	// Callers get their own copy, and only the one that ran the lookup reports the creation
+	return result.character.Clone(), result.created && leader, nil
+}
+
+type createResult struct {
+	character *domain.Character
+	created   bool
+}
+
+// createCharacter looks characterName up in the shared cache, locally, then
+// upstream, saving what the upstream returns.
+func (s *characterService) createCharacter(ctx context.Context, characterName string) (*domain.Character, bool, error) {
+	// 1. Check if character exists in the shared cache or the local database
+	cachedCharacter := s.fromCache(ctx, func(cache ports.CharacterCache) (*domain.Character, error) {
+		return cache.GetCharacterByName(ctx, characterName)
+	})
+	if cachedCharacter != nil {
+		s.logger.InfoContext(ctx, "Character found in shared cache", slog.String("character_name", characterName), slog.String("character_id", cachedCharacter.ID))
+		character, err := s.serveLocal(ctx, operationCreate, cachedCharacter, domain.CacheSourceCache)
+		return character, false, err
+	}
+	existingCharacter, err := s.characterRepository.FindCharacterByName(ctx, characterName)
 	if err == nil && existingCharacter != nil {
-		s.logger.Info("Character found in local database", slog.String("character_name", characterName), slog.String("character_id", existingCharacter.ID))
-		return existingCharacter, nil
+		s.logger.InfoContext(ctx, "Character found in local database", slog.String("character_name", characterName), slog.String("character_id", existingCharacter.ID))
+		s.cacheCharacter(ctx, existingCharacter)
+		character, err := s.serveLocal(ctx, operationCreate, existingCharacter, domain.CacheSourceLocal)
+		return character, false, err
 	}
 
-	// 2. If not found, fetch from external API
-	s.logger.Info("Character not found in local database, fetching from external API", slog.String("character_name", characterName))
-	apiCharacter, err := s.dragonBallAPIClient.FindCharacterByName(characterName)
+	// 2. If not found, fetch from external API unless it recently did not know the name
+	if s.isUnknownName(ctx, characterName) {
+		s.logger.InfoContext(ctx, "Character name recently not found in external API", slog.String("character_name", characterName))
+		s.recordLookup(ctx, operationCreate, domain.LookupKnownUnknown)
+		return nil, false, fmt.Errorf("character '%s' not found in external API: %w", characterName, domain.ErrNotFound)
+	}
+	s.logger.InfoContext(ctx, "Character not found in local database, fetching from external API", slog.String("character_name", characterName))
+	apiCharacter, err := s.dragonBallAPIClient.FindCharacterByName(ctx, characterName)
 	if err != nil {
-		s.logger.Error("Failed to fetch character from external API", slog.String("error", err.Error()), slog.String("character_name", characterName))
-		return nil, fmt.Errorf("failed to fetch character from external API: %w", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch character from external API", slog.String("error", err.Error()), slog.String("character_name", characterName))
+		return nil, false, fmt.Errorf("failed to fetch character from external API: %w", upstreamError(err))
 	}
 	if apiCharacter == nil {
-		s.logger.Warn("Character not found in external API", slog.String("character_name", characterName))
-		return nil, fmt.Errorf("character '%s' not found in external API", characterName)
+		s.logger.WarnContext(ctx, "Character not found in external API", slog.String("character_name", characterName))
+		s.saveUnknownName(ctx, characterName)
+		s.recordLookup(ctx, operationCreate, domain.LookupNotFound)
+		return nil, false, fmt.Errorf("character '%s' not found in external API: %w", characterName, domain.ErrNotFound)
 	}
 
-	// 3. Populate additional fields and save to database
-	newCharacter := &domain.Character{
-		ID:   apiCharacter.ID,
-		Name: apiCharacter.Name,
-		Ki:   apiCharacter.Ki,
-		Race: apiCharacter.Race,
+	// 3. Save the upstream profile to database
+	newCharacter := apiCharacter.Clone()
+
+	if err := s.characterRepository.SaveCharacter(ctx, newCharacter); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save character to database", slog.String("error", err.Error()), slog.String("character_name", newCharacter.Name))
+		return nil, false, fmt.Errorf("failed to save character: %w", err)
 	}
+	s.cacheCharacter(ctx, newCharacter)
 
-	if err := s.characterRepository.SaveCharacter(newCharacter); err != nil {
-		s.logger.Error("Failed to save character to database", slog.String("error", err.Error()), slog.String("character_name", newCharacter.Name))
-		return nil, fmt.Errorf("failed to save character: %w", err)
+	s.logger.InfoContext(ctx, "Successfully fetched and saved character", slog.String("character_name", newCharacter.Name), slog.String("character_id", newCharacter.ID))
+	s.recordLookup(ctx, operationCreate, domain.LookupUpstreamFetch)
+	newCharacter.Cache = &domain.CacheInfo{Source: domain.CacheSourceUpstream}
+	return newCharacter, true, nil
+}
+
+func (s *characterService) GetCharacter(ctx context.Context, id string) (character *domain.Character, err error) {
+	ctx, span := startSpan(ctx, "characterService.GetCharacter", attribute.String("character.id", id))
+	defer func() { endSpan(span, err) }()
+
+	s.logger.InfoContext(ctx, "Attempting to retrieve character by ID", slog.String("character_id", id))
+
+	// 1. Check if character exists in the shared cache or the local database
+	cachedCharacter := s.fromCache(ctx, func(cache ports.CharacterCache) (*domain.Character, error) {
+		return cache.GetCharacterByID(ctx, id)
+	})
+	if cachedCharacter != nil {
+		s.logger.InfoContext(ctx, "Character found in shared cache", slog.String("character_id", id), slog.String("character_name", cachedCharacter.Name))
+		return s.serveLocal(ctx, operationGet, cachedCharacter, domain.CacheSourceCache)
+	}
+	existingCharacter, err := s.characterRepository.FindCharacterByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to look up character in local database", slog.String("error", err.Error()), slog.String("character_id", id))
+		return nil, fmt.Errorf("failed to find character: %w", err)
+	}
+	if existingCharacter != nil {
+		s.logger.InfoContext(ctx, "Character found in local database", slog.String("character_id", id), slog.String("character_name", existingCharacter.Name))
+		s.cacheCharacter(ctx, existingCharacter)
+		return s.serveLocal(ctx, operationGet, existingCharacter, domain.CacheSourceLocal)
 	}
 
-	s.logger.Info("Successfully fetched and saved character", slog.String("character_name", newCharacter.Name), slog.String("character_id", newCharacter.ID))
+	// 2. If not found, fetch from external API and save it for future lookups
+	s.logger.InfoContext(ctx, "Character not found in local database, fetching from external API", slog.String("character_id", id))
+	newCharacter, err := s.fetchCharacterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if newCharacter == nil {
+		s.logger.WarnContext(ctx, "Character not found in external API", slog.String("character_id", id))
+		s.recordLookup(ctx, operationGet, domain.LookupNotFound)
+		return nil, fmt.Errorf("character '%s' not found: %w", id, domain.ErrNotFound)
+	}
+
+	s.logger.InfoContext(ctx, "Successfully fetched and saved character", slog.String("character_name", newCharacter.Name), slog.String("character_id", newCharacter.ID))
+	s.recordLookup(ctx, operationGet, domain.LookupUpstreamFetch)
+	newCharacter.Cache = &domain.CacheInfo{Source: domain.CacheSourceUpstream}
 	return newCharacter, nil
 }
+
+// serveLocal serves a character found in the shared cache or the local
+// database according to the freshness policy: fresh ones as they are, stale
+// ones right away while a background refresh updates them, expired ones only
+// once refreshed.
+func (s *characterService) serveLocal(ctx context.Context, operation string, character *domain.Character, source domain.CacheSource) (*domain.Character, error) {
+	age := time.Since(character.UpdatedAt)
+	switch s.freshness.Classify(character.UpdatedAt, time.Now()) {
+	case domain.Stale:
+		s.logger.InfoContext(ctx, "Serving stale character, refreshing it in the background", slog.String("character_id", character.ID), slog.Duration("age", age))
+		character.Cache = &domain.CacheInfo{Source: source, AgeSeconds: int64(age.Seconds()), Stale: true}
+		s.recordLookup(ctx, operation, domain.LookupStaleHit)
+		s.refreshInBackground(ctx, character.ID)
+		return character, nil
+	case domain.Expired:
+		s.logger.InfoContext(ctx, "Character is past its max age, refreshing it", slog.String("character_id", character.ID), slog.Duration("age", age))
+		refreshed, err := s.fetchCharacterByID(ctx, character.ID)
+		if err != nil {
+			return nil, err
+		}
+		if refreshed == nil {
+			// The upstream dropped the character, the local copy is all there is
+			s.logger.WarnContext(ctx, "Character to refresh not found in external API, serving local copy", slog.String("character_id", character.ID))
+			s.recordLookup(ctx, operation, hitOutcome(source))
+			character.Cache = &domain.CacheInfo{Source: source, AgeSeconds: int64(age.Seconds()), Stale: true}
+			return character, nil
+		}
+		s.recordLookup(ctx, operation, domain.LookupRefreshed)
+		refreshed.Cache = &domain.CacheInfo{Source: domain.CacheSourceUpstream}
+		return refreshed, nil
+	default:
+		s.recordLookup(ctx, operation, hitOutcome(source))
+		character.Cache = &domain.CacheInfo{Source: source, AgeSeconds: int64(age.Seconds())}
+		return character, nil
+	}
+}
+
+// refreshInBackground refreshes a stale character without holding up the
+// request, concurrent refreshes of the same character share one fetch.
+func (s *characterService) refreshInBackground(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundRefreshTimeout)
+	started := s.background.Go(func() {
+		defer cancel()
+		_, err, _ := s.refreshGroup.Do(id, func() (any, error) {
+			return s.fetchCharacterByID(ctx, id)
+		})
+		if err != nil {
+			s.logger.WarnContext(ctx, "Background refresh of stale character failed", slog.String("error", err.Error()), slog.String("character_id", id))
+			return
+		}
+		s.logger.InfoContext(ctx, "Refreshed stale character in the background", slog.String("character_id", id))
+	})
+	if !started {
+		cancel()
+		s.logger.InfoContext(ctx, "Shutting down, skipping background refresh of stale character", slog.String("character_id", id))
+	}
+}
+
+// Close waits until ctx ends for the background refreshes still running and
+// starts no new ones.
+func (s *characterService) Close(ctx context.Context) error {
+	return s.background.Close(ctx)
+}
+
+// fetchCharacterByID fetches a character from the external API and saves it,
+// returning nil when the external API does not know id.
+func (s *characterService) fetchCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	apiCharacter, err := s.dragonBallAPIClient.FindCharacterByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch character from external API", slog.String("error", err.Error()), slog.String("character_id", id))
+		return nil, fmt.Errorf("failed to fetch character from external API: %w", upstreamError(err))
+	}
+	if apiCharacter == nil {
+		return nil, nil
+	}
+
+	newCharacter := apiCharacter.Clone()
+	if err := s.characterRepository.SaveCharacter(ctx, newCharacter); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save character to database", slog.String("error", err.Error()), slog.String("character_id", newCharacter.ID))
+		return nil, fmt.Errorf("failed to save character: %w", err)
+	}
+	s.cacheCharacter(ctx, newCharacter)
+	return newCharacter, nil
+}
+
+// fromCache runs a shared cache lookup. The cache only saves work, so a
+// failing one is reported as a miss and the lookup goes on to the database.
+func (s *characterService) fromCache(ctx context.Context, get func(cache ports.CharacterCache) (*domain.Character, error)) *domain.Character {
+	if s.characterCache == nil {
+		return nil
+	}
+	character, err := get(s.characterCache)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Shared cache lookup failed, falling back to the database", slog.String("error", err.Error()))
+		return nil
+	}
+	return character
+}
+
+// cacheCharacter stores character in the shared cache for the other replicas,
+// a failure only costs them a database lookup.
+func (s *characterService) cacheCharacter(ctx context.Context, character *domain.Character) {
+	if s.characterCache == nil {
+		return
+	}
+	if err := s.characterCache.SetCharacter(ctx, character); err != nil {
+		s.logger.WarnContext(ctx, "Failed to store character in shared cache", slog.String("error", err.Error()), slog.String("character_id", character.ID))
+	}
+}
+
+func (s *characterService) ListCharacters(ctx context.Context, params domain.CharacterListParams) (page *domain.CharacterPage, err error) {
+	ctx, span := startSpan(ctx, "characterService.ListCharacters")
+	defer func() { endSpan(span, err) }()
+
+	if err := params.Normalize(); err != nil {
+		s.logger.WarnContext(ctx, "Invalid character list parameters", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "Listing characters from local database",
+		slog.String("race", params.Race),
+		slog.String("name_prefix", params.NamePrefix),
+		slog.String("sort_by", string(params.SortBy)),
+		slog.Int("limit", params.Limit),
+	)
+	page, err = s.characterRepository.ListCharacters(ctx, params)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list characters", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to list characters: %w", err)
+	}
+	return page, nil
+}
+
+func (s *characterService) PurgeUnknownNames(ctx context.Context) (purged int64, err error) {
+	ctx, span := startSpan(ctx, "characterService.PurgeUnknownNames")
+	defer func() { endSpan(span, err) }()
+
+	if s.unknownNames == nil {
+		return 0, nil
+	}
+	purged, err = s.unknownNames.PurgeUnknownNames(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge unknown character names", slog.String("error", err.Error()))
+		return 0, fmt.Errorf("failed to purge unknown character names: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Purged unknown character names", slog.Int64("purged", purged))
+	return purged, nil
+}
+
+// isUnknownName tells whether the external API recently did not know name.
+// A failing lookup only costs an upstream call, so it is reported as known.
+func (s *characterService) isUnknownName(ctx context.Context, name string) bool {
+	if s.unknownNames == nil || s.freshness.NotFoundTTL <= 0 {
+		return false
+	}
+	unknown, err := s.unknownNames.IsUnknownName(ctx, normalizeName(name))
+	if err != nil {
+		s.logger.WarnContext(ctx, "Failed to check unknown character names, asking the external API", slog.String("error", err.Error()), slog.String("character_name", name))
+		return false
+	}
+	return unknown
+}
+
+// saveUnknownName records that the external API does not know name, for
+// NotFoundTTL. A failure only costs an upstream call on the next lookup.
+func (s *characterService) saveUnknownName(ctx context.Context, name string) {
+	if s.unknownNames == nil || s.freshness.NotFoundTTL <= 0 {
+		return
+	}
+	if err := s.unknownNames.SaveUnknownName(ctx, normalizeName(name), s.freshness.NotFoundTTL); err != nil {
+		s.logger.WarnContext(ctx, "Failed to record unknown character name", slog.String("error", err.Error()), slog.String("character_name", name))
+	}
+}
+
+// recordLookup counts how a lookup was resolved and tags the current span
+// with it, telling local hits apart from upstream fetches in traces.
+func (s *characterService) recordLookup(ctx context.Context, operation string, outcome domain.LookupOutcome) {
+	s.metrics.RecordLookup(operation, outcome)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("character.lookup_outcome", string(outcome)))
+}
+
+func hitOutcome(source domain.CacheSource) domain.LookupOutcome {
+	if source == domain.CacheSourceCache {
+		return domain.LookupCacheHit
+	}
+	return domain.LookupLocalHit
+}
+
+// normalizeName is the key under which lookups of the same character are
+// coalesced, the local and upstream lookups both ignore case.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// upstreamError makes sure a failure reported by the external API client
+// matches one of the upstream sentinel errors, so adapters can tell it apart
+// from local failures.
+func upstreamError(err error) error {
+	if errors.Is(err, domain.ErrUpstreamTimeout) || errors.Is(err, domain.ErrUpstreamUnavailable) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", domain.ErrUpstreamUnavailable, err)
+}
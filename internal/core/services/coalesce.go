@@ -0,0 +1,31 @@
+package services
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// coalesce runs fetch once for the concurrent calls sharing key in group and
+// hands its result to each of them, reporting whether this call is the one
+// that ran it. fetch is detached from the caller's cancellation, since other
+// callers may still be waiting for it; a caller whose ctx ends stops waiting
+// and gets ctx.Err(). Results are shared, callers must copy before mutating.
+func coalesce[T any](ctx context.Context, group *singleflight.Group, key string, fetch func(context.Context) (T, error)) (result T, leader bool, err error) {
+	ran := false
+	results := group.DoChan(key, func() (any, error) {
+		ran = true
+		return fetch(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return result, false, ctx.Err()
+	case res := <-results:
+		// ran is only written before the result is sent, reading it here is safe
+		if res.Err != nil {
+			return result, ran, res.Err
+		}
+		return res.Val.(T), ran, nil
+	}
+}
@@ -0,0 +1,402 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"log/slog"
+
+	"backend.go.characters.api/internal/core/domain"
+	"backend.go.characters.api/internal/core/ports"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// planetListKey is the fetchGroup key of the planet list import.
+	planetListKey = "list"
+	// planetCharacterFetchConcurrency bounds the upstream fetches of one
+	// planet character import.
+	planetCharacterFetchConcurrency = 4
+)
+
+type planetService struct {
+	planetRepository    ports.PlanetRepository
+	characterRepository ports.CharacterRepository
+	// invalidators are the caches in front of the characters, whose copies
+	// of linked characters are dropped
+	invalidators        []ports.CharacterInvalidator
+	dragonBallAPIClient ports.DragonBallAPIClient
+	freshness           domain.FreshnessPolicy
+	logger              *slog.Logger
+	fetchGroup          singleflight.Group
+	background          backgroundTasks
+}
+
+// NewPlanetService builds the service. Planets are served from the local
+// database and fetched from the external API on a miss, then refreshed under
+// freshness like characters. The planet list is imported again once freshness
+// classifies it stale or expired. characterCache is optional, like for the
+// character service. When characterRepository is a cache itself, its copies
+// of the characters linked to a planet are invalidated along with those of
+// characterCache.
+func NewPlanetService(
+	planetRepository ports.PlanetRepository,
+	characterRepository ports.CharacterRepository,
+	characterCache ports.CharacterCache,
+	dragonBallAPIClient ports.DragonBallAPIClient,
+	freshness domain.FreshnessPolicy,
+	logger *slog.Logger,
+) ports.PlanetService {
+	var invalidators []ports.CharacterInvalidator
+	if invalidator, ok := characterRepository.(ports.CharacterInvalidator); ok {
+		invalidators = append(invalidators, invalidator)
+	}
+	if characterCache != nil {
+		invalidators = append(invalidators, characterCache)
+	}
+	return &planetService{
+		planetRepository:    planetRepository,
+		characterRepository: characterRepository,
+		invalidators:        invalidators,
+		dragonBallAPIClient: dragonBallAPIClient,
+		freshness:           freshness,
+		logger:              logger,
+	}
+}
+
+func (s *planetService) ListPlanets(ctx context.Context) (planets []*domain.Planet, err error) {
+	ctx, span := startSpan(ctx, "planetService.ListPlanets")
+	defer func() { endSpan(span, err) }()
+
+	list, err := s.planetRepository.ListPlanets(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list planets", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to list planets: %w", err)
+	}
+	if list.ImportedAt.IsZero() {
+		s.logger.InfoContext(ctx, "Planet list not imported yet, fetching it from external API")
+	} else {
+		age := time.Since(list.ImportedAt)
+		switch s.freshness.Classify(list.ImportedAt, time.Now()) {
+		case domain.Stale:
+			s.logger.InfoContext(ctx, "Serving stale planet list, importing it again in the background", slog.Duration("age", age))
+			s.importInBackground(ctx)
+			return list.Planets, nil
+		case domain.Expired:
+			s.logger.InfoContext(ctx, "Planet list is past its max age, importing it again", slog.Duration("age", age))
+		default:
+			return list.Planets, nil
+		}
+	}
+
+	// Concurrent calls share a single import
+	imported, _, err := coalesce(ctx, &s.fetchGroup, planetListKey, s.importPlanetList)
+	if err != nil {
+		s.logWaitError(ctx, err, planetListKey)
+		return nil, err
+	}
+	planets = []*domain.Planet{}
+	for _, planet := range imported {
+		planets = append(planets, planet.Clone())
+	}
+	return planets, nil
+}
+
+// importInBackground imports the planet list again without holding up the
+// request, sharing the import with any other one in flight.
+func (s *planetService) importInBackground(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundRefreshTimeout)
+	started := s.background.Go(func() {
+		defer cancel()
+		if _, _, err := coalesce(ctx, &s.fetchGroup, planetListKey, s.importPlanetList); err != nil {
+			s.logger.WarnContext(ctx, "Background import of stale planet list failed", slog.String("error", err.Error()))
+			return
+		}
+		s.logger.InfoContext(ctx, "Imported stale planet list in the background")
+	})
+	if !started {
+		cancel()
+		s.logger.InfoContext(ctx, "Shutting down, skipping background import of stale planet list")
+	}
+}
+
+func (s *planetService) importPlanetList(ctx context.Context) ([]*domain.Planet, error) {
+	planets, err := s.dragonBallAPIClient.ListPlanets(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch planets from external API", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to fetch planets from external API: %w", upstreamError(err))
+	}
+	if err := s.planetRepository.SavePlanetList(ctx, planets); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save planet list to database", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("failed to save planets: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Successfully fetched and saved planet list", slog.Int("count", len(planets)))
+	return planets, nil
+}
+
+func (s *planetService) GetPlanet(ctx context.Context, id string) (planet *domain.Planet, err error) {
+	ctx, span := startSpan(ctx, "planetService.GetPlanet", attribute.String("planet.id", id))
+	defer func() { endSpan(span, err) }()
+
+	existingPlanet, err := s.planetRepository.FindPlanetByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to look up planet in local database", slog.String("error", err.Error()), slog.String("planet_id", id))
+		return nil, fmt.Errorf("failed to find planet: %w", err)
+	}
+	if existingPlanet != nil {
+		s.logger.InfoContext(ctx, "Planet found in local database", slog.String("planet_id", id), slog.String("planet_name", existingPlanet.Name))
+		return s.serveLocal(ctx, existingPlanet)
+	}
+
+	s.logger.InfoContext(ctx, "Planet not found in local database, fetching from external API", slog.String("planet_id", id))
+	newPlanet, err := s.sharedFetchPlanetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if newPlanet == nil {
+		s.logger.WarnContext(ctx, "Planet not found in external API", slog.String("planet_id", id))
+		return nil, fmt.Errorf("planet '%s' not found: %w", id, domain.ErrNotFound)
+	}
+
+	planet = newPlanet.Clone()
+	planet.Cache = &domain.CacheInfo{Source: domain.CacheSourceUpstream}
+	return planet, nil
+}
+
+// serveLocal serves a planet found in the local database according to the
+// freshness policy, like characterService.serveLocal: fresh ones as they are,
+// stale ones right away while a background refresh updates them, expired ones
+// only once refreshed.
+func (s *planetService) serveLocal(ctx context.Context, planet *domain.Planet) (*domain.Planet, error) {
+	age := time.Since(planet.UpdatedAt)
+	switch s.freshness.Classify(planet.UpdatedAt, time.Now()) {
+	case domain.Stale:
+		s.logger.InfoContext(ctx, "Serving stale planet, refreshing it in the background", slog.String("planet_id", planet.ID), slog.Duration("age", age))
+		planet.Cache = &domain.CacheInfo{Source: domain.CacheSourceLocal, AgeSeconds: int64(age.Seconds()), Stale: true}
+		s.refreshInBackground(ctx, planet.ID)
+		return planet, nil
+	case domain.Expired:
+		s.logger.InfoContext(ctx, "Planet is past its max age, refreshing it", slog.String("planet_id", planet.ID), slog.Duration("age", age))
+		refreshed, err := s.sharedFetchPlanetByID(ctx, planet.ID)
+		if err != nil {
+			return nil, err
+		}
+		if refreshed == nil {
+			// The upstream dropped the planet, the local copy is all there is
+			s.logger.WarnContext(ctx, "Planet to refresh not found in external API, serving local copy", slog.String("planet_id", planet.ID))
+			planet.Cache = &domain.CacheInfo{Source: domain.CacheSourceLocal, AgeSeconds: int64(age.Seconds()), Stale: true}
+			return planet, nil
+		}
+		refreshed = refreshed.Clone()
+		// Saving the planet leaves the import of its characters as it was
+		refreshed.CharactersImportedAt = planet.CharactersImportedAt
+		refreshed.Cache = &domain.CacheInfo{Source: domain.CacheSourceUpstream}
+		return refreshed, nil
+	default:
+		planet.Cache = &domain.CacheInfo{Source: domain.CacheSourceLocal, AgeSeconds: int64(age.Seconds())}
+		return planet, nil
+	}
+}
+
+// refreshInBackground refreshes a stale planet without holding up the
+// request, sharing the fetch with any other one in flight.
+func (s *planetService) refreshInBackground(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundRefreshTimeout)
+	started := s.background.Go(func() {
+		defer cancel()
+		if _, _, err := coalesce(ctx, &s.fetchGroup, "id:"+id, func(ctx context.Context) (*domain.Planet, error) {
+			return s.fetchPlanetByID(ctx, id)
+		}); err != nil {
+			s.logger.WarnContext(ctx, "Background refresh of stale planet failed", slog.String("error", err.Error()), slog.String("planet_id", id))
+			return
+		}
+		s.logger.InfoContext(ctx, "Refreshed stale planet in the background", slog.String("planet_id", id))
+	})
+	if !started {
+		cancel()
+		s.logger.InfoContext(ctx, "Shutting down, skipping background refresh of stale planet", slog.String("planet_id", id))
+	}
+}
+
+// Close waits until ctx ends for the background imports and refreshes still
+// running and starts no new ones.
+func (s *planetService) Close(ctx context.Context) error {
+	return s.background.Close(ctx)
+}
+
+// sharedFetchPlanetByID runs fetchPlanetByID once for the concurrent calls
+// asking for id. The planet returned is shared, callers clone it.
+func (s *planetService) sharedFetchPlanetByID(ctx context.Context, id string) (*domain.Planet, error) {
+	planet, _, err := coalesce(ctx, &s.fetchGroup, "id:"+id, func(ctx context.Context) (*domain.Planet, error) {
+		return s.fetchPlanetByID(ctx, id)
+	})
+	if err != nil {
+		s.logWaitError(ctx, err, "id:"+id)
+		return nil, err
+	}
+	return planet, nil
+}
+
+// fetchPlanetByID fetches a planet from the external API and saves it,
+// returning nil when the external API does not know id.
+func (s *planetService) fetchPlanetByID(ctx context.Context, id string) (*domain.Planet, error) {
+	apiPlanet, err := s.dragonBallAPIClient.FindPlanetByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch planet from external API", slog.String("error", err.Error()), slog.String("planet_id", id))
+		return nil, fmt.Errorf("failed to fetch planet from external API: %w", upstreamError(err))
+	}
+	if apiPlanet == nil {
+		return nil, nil
+	}
+	if err := s.planetRepository.SavePlanet(ctx, apiPlanet); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save planet to database", slog.String("error", err.Error()), slog.String("planet_id", id))
+		return nil, fmt.Errorf("failed to save planet: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Successfully fetched and saved planet", slog.String("planet_id", apiPlanet.ID), slog.String("planet_name", apiPlanet.Name))
+	return apiPlanet, nil
+}
+
+// logWaitError logs a caller giving up on a shared fetch, whose own failures
+// are logged where they happen.
+func (s *planetService) logWaitError(ctx context.Context, err error, key string) {
+	if ctxErr := ctx.Err(); ctxErr != nil && err == ctxErr {
+		s.logger.WarnContext(ctx, "Gave up waiting for planet lookup", slog.String("error", ctxErr.Error()), slog.String("key", key))
+	}
+}
+
+func (s *planetService) ListPlanetCharacters(ctx context.Context, id string, params domain.CharacterListParams) (page *domain.CharacterPage, err error) {
+	ctx, span := startSpan(ctx, "planetService.ListPlanetCharacters", attribute.String("planet.id", id))
+	defer func() { endSpan(span, err) }()
+
+	// An unknown planet is a 404 rather than an empty page
+	planet, err := s.GetPlanet(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	params.OriginPlanetID = id
+	if err := params.Normalize(); err != nil {
+		s.logger.WarnContext(ctx, "Invalid character list parameters", slog.String("error", err.Error()))
+		return nil, err
+	}
+
+	// The characters are imported the first time, and again once past the
+	// max age, so the page does not only hold those looked up by chance
+	if planet.CharactersImportedAt == nil || s.freshness.Classify(*planet.CharactersImportedAt, time.Now()) == domain.Expired {
+		s.logger.InfoContext(ctx, "Planet characters not imported recently, fetching them from external API", slog.String("planet_id", id))
+		key := "characters:" + id
+		if _, _, err := coalesce(ctx, &s.fetchGroup, key, func(ctx context.Context) (int, error) {
+			return s.importPlanetCharacters(ctx, planet)
+		}); err != nil {
+			s.logWaitError(ctx, err, key)
+			return nil, err
+		}
+	}
+
+	page, err = s.characterRepository.ListCharacters(ctx, params)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list planet characters", slog.String("error", err.Error()), slog.String("planet_id", id))
+		return nil, fmt.Errorf("failed to list planet characters: %w", err)
+	}
+	return page, nil
+}
+
+// importPlanetCharacters links the stored characters the external API lists
+// for planet and saves the others, fetching each one for its full profile
+// with at most planetCharacterFetchConcurrency fetches at a time. It returns
+// how many were saved. A character that fails to import is logged and left
+// out, and the import is then not marked done so the next listing retries it.
+func (s *planetService) importPlanetCharacters(ctx context.Context, planet *domain.Planet) (int, error) {
+	characterIDs, err := s.dragonBallAPIClient.ListPlanetCharacterIDs(ctx, planet.ID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch planet characters from external API", slog.String("error", err.Error()), slog.String("planet_id", planet.ID))
+		return 0, fmt.Errorf("failed to fetch planet characters from external API: %w", upstreamError(err))
+	}
+
+	stored, err := s.planetRepository.LinkPlanetCharacters(ctx, planet.ID, characterIDs)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to link stored planet characters", slog.String("error", err.Error()), slog.String("planet_id", planet.ID))
+		return 0, fmt.Errorf("failed to link planet characters: %w", err)
+	}
+	s.invalidateCharacters(ctx, stored)
+	storedIDs := make(map[string]bool, len(stored))
+	for _, characterID := range stored {
+		storedIDs[characterID] = true
+	}
+
+	var (
+		mu               sync.Mutex
+		imported, failed int
+		group            errgroup.Group
+	)
+	group.SetLimit(planetCharacterFetchConcurrency)
+	for _, characterID := range characterIDs {
+		if storedIDs[characterID] {
+			continue
+		}
+		group.Go(func() error {
+			saved, err := s.importPlanetCharacter(ctx, planet, characterID)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+			} else if saved {
+				imported++
+			}
+			return nil
+		})
+	}
+	group.Wait()
+
+	if failed > 0 {
+		s.logger.WarnContext(ctx, "Some planet characters could not be imported, retrying on next listing", slog.String("planet_id", planet.ID), slog.Int("listed", len(characterIDs)), slog.Int("imported", imported), slog.Int("failed", failed))
+		return imported, nil
+	}
+	if err := s.planetRepository.MarkPlanetCharactersImported(ctx, planet.ID); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to mark planet characters as imported", slog.String("error", err.Error()), slog.String("planet_id", planet.ID))
+		return imported, fmt.Errorf("failed to mark planet characters as imported: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Successfully imported planet characters", slog.String("planet_id", planet.ID), slog.Int("listed", len(characterIDs)), slog.Int("linked", len(stored)), slog.Int("imported", imported))
+	return imported, nil
+}
+
+// invalidateCharacters drops the cached copies of the characters ids, which
+// may lack the origin planet they were just linked to. A cache that fails to
+// drop them is logged, its copies expire with their TTL.
+func (s *planetService) invalidateCharacters(ctx context.Context, ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	for _, invalidator := range s.invalidators {
+		if err := invalidator.InvalidateCharacters(ctx, ids); err != nil {
+			s.logger.WarnContext(ctx, "Failed to invalidate cached planet characters", slog.String("error", err.Error()), slog.Int("count", len(ids)))
+		}
+	}
+}
+
+// importPlanetCharacter fetches the character id listed for planet and saves
+// it, reporting whether the external API knew it. A profile without an origin
+// planet gets planet, so the character is listed with it.
+func (s *planetService) importPlanetCharacter(ctx context.Context, planet *domain.Planet, id string) (bool, error) {
+	apiCharacter, err := s.dragonBallAPIClient.FindCharacterByID(ctx, id)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to fetch planet character from external API", slog.String("error", err.Error()), slog.String("character_id", id), slog.String("planet_id", planet.ID))
+		return false, err
+	}
+	if apiCharacter == nil {
+		return false, nil
+	}
+	character := apiCharacter.Clone()
+	if character.OriginPlanet == nil {
+		character.OriginPlanet = planet.Clone()
+	}
+	if err := s.characterRepository.SaveCharacter(ctx, character); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save planet character to database", slog.String("error", err.Error()), slog.String("character_id", id), slog.String("planet_id", planet.ID))
+		return false, err
+	}
+	return true, nil
+}
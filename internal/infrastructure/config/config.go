@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +20,53 @@ type Config struct {
 	DBHost      string
 	DBPort      string
 	LogLevel    string
+
+	LogFormat             string
+	LogAddSource          bool
+	LogFile               string
+	LogFileMaxSizeMB      int
+	LogFileMaxBackups     int
+	LogSamplingInitial    int
+	LogSamplingThereafter int
+	LogSamplingTick       time.Duration
+	AccessLogSkipPaths    []string
+
+	// AdminToken protects the /admin routes, left unserved when empty.
+	AdminToken string
+
+	MigrateOnStartup bool
+	ShutdownTimeout  time.Duration
+	ShutdownDelay    time.Duration
+
+	CharacterFreshTTL time.Duration
+	CharacterMaxAge   time.Duration
+	// CharacterNotFoundTTL is how long a name unknown upstream is answered
+	// as not found without asking it again.
+	CharacterNotFoundTTL time.Duration
+
+	RepositoryCacheSize     int
+	RepositoryCacheMaxBytes int
+	RepositoryCacheTTL      time.Duration
+
+	RedisURL       string
+	RedisCacheTTL  time.Duration
+	RedisKeyPrefix string
+
+	DragonBallAPIBaseURL               string
+	DragonBallAPIRequestTimeout        time.Duration
+	DragonBallAPIUserAgent             string
+	DragonBallAPIMaxIdleConns          int
+	DragonBallAPITLSCAFile             string
+	DragonBallAPITLSInsecureSkipVerify bool
+	DragonBallAPIMaxRetries            int
+	DragonBallAPIRetryBaseDelay        time.Duration
+	DragonBallAPIRetryMaxDelay         time.Duration
+	DragonBallAPIBreakerThreshold      int
+	DragonBallAPIBreakerOpenTimeout    time.Duration
+
+	TracingExporter    string
+	TracingServiceName string
+	TracingSampleRatio float64
 }
 
 func LoadConfig() (*Config, error) {
@@ -33,12 +83,39 @@ func LoadConfig() (*Config, error) {
 		DBHost:     os.Getenv("DB_HOST"),
 		DBPort:     os.Getenv("DB_PORT"),
 		LogLevel:   os.Getenv("LOG_LEVEL"),
+		LogFormat:  os.Getenv("LOG_FORMAT"),
+		LogFile:    os.Getenv("LOG_FILE"),
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+
+		DragonBallAPIBaseURL:   os.Getenv("DRAGONBALL_API_BASE_URL"),
+		DragonBallAPIUserAgent: os.Getenv("DRAGONBALL_API_USER_AGENT"),
+		DragonBallAPITLSCAFile: os.Getenv("DRAGONBALL_API_TLS_CA_FILE"),
+
+		RedisURL:       os.Getenv("REDIS_URL"),
+		RedisKeyPrefix: os.Getenv("REDIS_KEY_PREFIX"),
+
+		TracingExporter:    os.Getenv("OTEL_TRACES_EXPORTER"),
+		TracingServiceName: os.Getenv("OTEL_SERVICE_NAME"),
 	}
 
 	if cfg.Port == "" {
 		cfg.Port = "8080" // Default port
 	}
 
+	if cfg.LogLevel == "" {
+		cfg.LogLevel = "INFO"
+	}
+	if cfg.LogFormat == "" {
+		cfg.LogFormat = "json"
+	}
+	cfg.AccessLogSkipPaths = getEnvList("ACCESS_LOG_SKIP_PATHS", []string{"/healthz", "/readyz", "/metrics"})
+	if cfg.TracingExporter == "" {
+		cfg.TracingExporter = "none"
+	}
+	if cfg.TracingServiceName == "" {
+		cfg.TracingServiceName = "backend.go.characters.api"
+	}
+
 	if cfg.DBUser == "" || cfg.DBPassword == "" || cfg.DBName == "" || cfg.DBHost == "" || cfg.DBPort == "" {
 		return nil, fmt.Errorf("database environment variables (DB_USER, DB_PASSWORD, DB_NAME, DB_HOST, DB_PORT) must be set")
 	}
@@ -46,5 +123,154 @@ func LoadConfig() (*Config, error) {
 	cfg.DatabaseURL = fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
 		cfg.DBUser, cfg.DBPassword, cfg.DBHost, cfg.DBPort, cfg.DBName)
 
+	var err error
+	if cfg.LogAddSource, err = getEnvBool("LOG_ADD_SOURCE", true); err != nil {
+		return nil, err
+	}
+	if cfg.LogFileMaxSizeMB, err = getEnvInt("LOG_FILE_MAX_SIZE_MB", 100); err != nil {
+		return nil, err
+	}
+	if cfg.LogFileMaxBackups, err = getEnvInt("LOG_FILE_MAX_BACKUPS", 5); err != nil {
+		return nil, err
+	}
+	if cfg.LogSamplingInitial, err = getEnvInt("LOG_SAMPLING_INITIAL", 0); err != nil {
+		return nil, err
+	}
+	if cfg.LogSamplingThereafter, err = getEnvInt("LOG_SAMPLING_THEREAFTER", 100); err != nil {
+		return nil, err
+	}
+	if cfg.LogSamplingTick, err = getEnvDuration("LOG_SAMPLING_TICK", time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.MigrateOnStartup, err = getEnvBool("MIGRATE_ON_STARTUP", true); err != nil {
+		return nil, err
+	}
+	if cfg.ShutdownTimeout, err = getEnvDuration("SHUTDOWN_TIMEOUT", 15*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.ShutdownDelay, err = getEnvDuration("SHUTDOWN_DELAY", 0); err != nil {
+		return nil, err
+	}
+	if cfg.CharacterFreshTTL, err = getEnvDuration("CHARACTER_FRESH_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.CharacterMaxAge, err = getEnvDuration("CHARACTER_MAX_AGE", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.CharacterNotFoundTTL, err = getEnvDuration("CHARACTER_NOT_FOUND_TTL", 10*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.RepositoryCacheSize, err = getEnvInt("REPOSITORY_CACHE_SIZE", 1000); err != nil {
+		return nil, err
+	}
+	if cfg.RepositoryCacheMaxBytes, err = getEnvInt("REPOSITORY_CACHE_MAX_BYTES", 64<<20); err != nil {
+		return nil, err
+	}
+	if cfg.RepositoryCacheTTL, err = getEnvDuration("REPOSITORY_CACHE_TTL", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.RedisCacheTTL, err = getEnvDuration("REDIS_CACHE_TTL", 10*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPIRequestTimeout, err = getEnvDuration("DRAGONBALL_API_REQUEST_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPIMaxIdleConns, err = getEnvInt("DRAGONBALL_API_MAX_IDLE_CONNS", 10); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPITLSInsecureSkipVerify, err = getEnvBool("DRAGONBALL_API_TLS_INSECURE_SKIP_VERIFY", false); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPIMaxRetries, err = getEnvInt("DRAGONBALL_API_MAX_RETRIES", 3); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPIRetryBaseDelay, err = getEnvDuration("DRAGONBALL_API_RETRY_BASE_DELAY", 200*time.Millisecond); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPIRetryMaxDelay, err = getEnvDuration("DRAGONBALL_API_RETRY_MAX_DELAY", 5*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPIBreakerThreshold, err = getEnvInt("DRAGONBALL_API_BREAKER_THRESHOLD", 5); err != nil {
+		return nil, err
+	}
+	if cfg.DragonBallAPIBreakerOpenTimeout, err = getEnvDuration("DRAGONBALL_API_BREAKER_OPEN_TIMEOUT", 30*time.Second); err != nil {
+		return nil, err
+	}
+
+	if cfg.TracingSampleRatio, err = getEnvFloat("OTEL_TRACES_SAMPLER_ARG", 1); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
+
+// getEnvInt reads an integer environment variable, falling back to def when unset.
+func getEnvInt(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s must be an integer: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// getEnvList reads a comma separated environment variable, falling back to def
+// when unset. Set it to "-" for an empty list.
+func getEnvList(key string, def []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" && item != "-" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvFloat reads a floating point environment variable, falling back to
+// def when unset.
+func getEnvFloat(key string, def float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s must be a number: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// getEnvBool reads a boolean environment variable such as "true" or "0",
+// falling back to def when unset.
+func getEnvBool(key string, def bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("environment variable %s must be a boolean: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// getEnvDuration reads a duration environment variable such as "250ms" or
+// "30s", falling back to def when unset.
+func getEnvDuration(key string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("environment variable %s must be a duration: %w", key, err)
+	}
+	return parsed, nil
+}
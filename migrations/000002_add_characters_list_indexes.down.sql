@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_characters_race;
+DROP INDEX IF EXISTS idx_characters_updated_at;
+DROP INDEX IF EXISTS idx_characters_created_at;
+DROP INDEX IF EXISTS idx_characters_name;
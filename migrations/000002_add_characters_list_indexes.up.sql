@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_characters_name ON characters (name, id);
+CREATE INDEX IF NOT EXISTS idx_characters_created_at ON characters (created_at, id);
+CREATE INDEX IF NOT EXISTS idx_characters_updated_at ON characters (updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_characters_race ON characters (LOWER(race));
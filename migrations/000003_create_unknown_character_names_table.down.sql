@@ -0,0 +1 @@
+DROP TABLE IF EXISTS unknown_character_names;
@@ -0,0 +1,6 @@
+-- Names the Dragon Ball API does not know, answered with 404 until they expire
+CREATE TABLE IF NOT EXISTS unknown_character_names (
+    name VARCHAR(255) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
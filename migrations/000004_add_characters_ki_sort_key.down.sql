@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_characters_ki;
+ALTER TABLE characters DROP COLUMN IF EXISTS ki_significand;
+ALTER TABLE characters DROP COLUMN IF EXISTS ki_magnitude;
@@ -0,0 +1,7 @@
+-- The power level as a (magnitude, significand) pair, so that values up to a
+-- googolplex order exactly. Unknown power levels are -2, zero is -1. Existing
+-- rows start with the unknown key, BackfillKiSortKeys sets theirs once the
+-- migrations are applied.
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS ki_magnitude NUMERIC NOT NULL DEFAULT -2;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS ki_significand NUMERIC NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_characters_ki ON characters (ki_magnitude, ki_significand, id);
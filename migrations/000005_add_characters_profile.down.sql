@@ -0,0 +1,8 @@
+ALTER TABLE characters DROP COLUMN IF EXISTS transformations;
+ALTER TABLE characters DROP COLUMN IF EXISTS origin_planet;
+ALTER TABLE characters DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE characters DROP COLUMN IF EXISTS affiliation;
+ALTER TABLE characters DROP COLUMN IF EXISTS image_url;
+ALTER TABLE characters DROP COLUMN IF EXISTS description;
+ALTER TABLE characters DROP COLUMN IF EXISTS gender;
+ALTER TABLE characters DROP COLUMN IF EXISTS max_ki;
@@ -0,0 +1,10 @@
+-- The rest of the upstream profile. The origin planet and the transformations
+-- are kept as the upstream nests them, existing rows get them when refreshed.
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS max_ki VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS gender VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS image_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS affiliation VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS origin_planet JSONB;
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS transformations JSONB NOT NULL DEFAULT '[]';
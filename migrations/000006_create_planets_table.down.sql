@@ -0,0 +1,14 @@
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS origin_planet JSONB;
+UPDATE characters c SET origin_planet = jsonb_strip_nulls(jsonb_build_object(
+    'id', p.id,
+    'name', p.name,
+    'is_destroyed', p.is_destroyed,
+    'description', p.description,
+    'image_url', p.image_url,
+    'deleted_at', p.deleted_at
+))
+FROM planets p
+WHERE p.id = c.origin_planet_id;
+DROP INDEX IF EXISTS idx_characters_origin_planet_id;
+ALTER TABLE characters DROP COLUMN IF EXISTS origin_planet_id;
+DROP TABLE IF EXISTS planets;
@@ -0,0 +1,33 @@
+-- Planets, referenced by the characters originating from them. listed_at is
+-- set on the planets of the imported upstream list.
+CREATE TABLE IF NOT EXISTS planets (
+    id VARCHAR(255) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    is_destroyed BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    deleted_at TIMESTAMP WITH TIME ZONE,
+    listed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_planets_name ON planets (name, id);
+
+-- The origin planets stored inline until now become rows
+INSERT INTO planets (id, name, is_destroyed, description, image_url, deleted_at)
+SELECT DISTINCT ON (origin_planet->>'id')
+    origin_planet->>'id',
+    origin_planet->>'name',
+    COALESCE((origin_planet->>'is_destroyed')::BOOLEAN, FALSE),
+    COALESCE(origin_planet->>'description', ''),
+    COALESCE(origin_planet->>'image_url', ''),
+    (origin_planet->>'deleted_at')::TIMESTAMP WITH TIME ZONE
+FROM characters
+WHERE origin_planet IS NOT NULL
+ORDER BY origin_planet->>'id', updated_at DESC
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE characters ADD COLUMN IF NOT EXISTS origin_planet_id VARCHAR(255) REFERENCES planets (id);
+UPDATE characters SET origin_planet_id = origin_planet->>'id' WHERE origin_planet IS NOT NULL;
+ALTER TABLE characters DROP COLUMN IF EXISTS origin_planet;
+CREATE INDEX IF NOT EXISTS idx_characters_origin_planet_id ON characters (origin_planet_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_unknown_character_names_expires_at;
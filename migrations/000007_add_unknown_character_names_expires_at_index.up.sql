@@ -0,0 +1,2 @@
+-- Expired names are deleted whenever a name is recorded
+CREATE INDEX IF NOT EXISTS idx_unknown_character_names_expires_at ON unknown_character_names (expires_at);
@@ -0,0 +1,2 @@
+-- When the characters the upstream lists for the planet were last imported
+ALTER TABLE planets ADD COLUMN IF NOT EXISTS characters_imported_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS planet_list_imports;
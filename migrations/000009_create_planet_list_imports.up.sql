@@ -0,0 +1,9 @@
+-- When the planet list was last imported, also when the upstream list was empty
+CREATE TABLE IF NOT EXISTS planet_list_imports (
+    singleton BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (singleton),
+    imported_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+INSERT INTO planet_list_imports (imported_at)
+SELECT MIN(listed_at) FROM planets WHERE listed_at IS NOT NULL HAVING COUNT(*) > 0
+ON CONFLICT (singleton) DO NOTHING;
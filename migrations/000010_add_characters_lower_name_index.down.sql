@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_characters_lower_name;
@@ -0,0 +1,2 @@
+-- Characters are looked up by name ignoring case
+CREATE INDEX IF NOT EXISTS idx_characters_lower_name ON characters (LOWER(name));
@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned SQL migrations of the service.
+//
+// Files are named NNNNNN_description.up.sql and NNNNNN_description.down.sql,
+// where NNNNNN is the version. Applied migrations are tracked in the
+// schema_migrations table; never edit a migration once it has been applied.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
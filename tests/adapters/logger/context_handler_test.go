@@ -0,0 +1,37 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"backend.go.characters.api/internal/adapters/logger"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestContextHandlerAddsCorrelationIDs(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(logger.NewContextHandler(slog.NewJSONHandler(&buf, nil))).With(slog.String("component", "test"))
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	ctx = logger.WithRequestID(ctx, "req-123")
+
+	log.InfoContext(ctx, "with context")
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "req-123", record["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Equal(t, "test", record["component"])
+
+	buf.Reset()
+	log.Info("without context")
+	record = map[string]any{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.NotContains(t, record, "request_id")
+	assert.NotContains(t, record, "trace_id")
+}
@@ -0,0 +1,122 @@
+package logger_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return strings.Split(strings.TrimSpace(string(content)), "\n")
+}
+
+func TestLoggerHonoursLevelVarAndFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	level := new(slog.LevelVar)
+	log, closer, err := logger.New(logger.Options{Level: level, Format: logger.FormatText, File: logger.FileOptions{Path: path}})
+	assert.NoError(t, err)
+
+	log.Debug("hidden")
+	level.Set(slog.LevelDebug)
+	log.Debug("visible")
+	assert.NoError(t, closer.Close())
+
+	lines := readLines(t, path)
+	if assert.Len(t, lines, 1) {
+		assert.Contains(t, lines[0], "level=DEBUG msg=visible")
+	}
+}
+
+func TestLoggerRejectsUnknownFormat(t *testing.T) {
+	_, _, err := logger.New(logger.Options{Format: "xml"})
+	assert.Error(t, err)
+
+	_, err = logger.ParseLevel("verbose")
+	assert.Error(t, err)
+	level, err := logger.ParseLevel("warn")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+}
+
+func TestLoggerRotatesFileBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, closer, err := logger.New(logger.Options{File: logger.FileOptions{Path: path, MaxSizeMB: 1, MaxBackups: 2}})
+	assert.NoError(t, err)
+
+	// Roughly 3.5 MB of logs fill the file and both backups
+	payload := strings.Repeat("x", 1024)
+	for i := 0; i < 3500; i++ {
+		log.Info("filler", slog.String("payload", payload))
+	}
+	assert.NoError(t, closer.Close())
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if assert.NoError(t, err) {
+			assert.LessOrEqual(t, info.Size(), int64(1<<20))
+		}
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err), "only MaxBackups old files are kept")
+}
+
+func TestLoggerKeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	// A non-empty directory in place of the first backup makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "keep"), 0o755); err != nil {
+		t.Fatalf("failed to block the backup: %v", err)
+	}
+	log, closer, err := logger.New(logger.Options{File: logger.FileOptions{Path: path, MaxSizeMB: 1, MaxBackups: 1}})
+	assert.NoError(t, err)
+
+	payload := strings.Repeat("x", 1024)
+	for i := 0; i < 1100; i++ {
+		log.Info("filler", slog.String("payload", payload))
+	}
+	log.Info("after failed rotation")
+	assert.NoError(t, closer.Close())
+
+	lines := readLines(t, path)
+	assert.Len(t, lines, 1101)
+	assert.Contains(t, lines[len(lines)-1], `"msg":"after failed rotation"`)
+}
+
+func TestLoggerSamplesRepetitiveInfoLogs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	log, closer, err := logger.New(logger.Options{
+		File:     logger.FileOptions{Path: path},
+		Sampling: logger.SamplingOptions{Initial: 3, Thereafter: 5, Tick: time.Hour},
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 20; i++ {
+		log.Info("repeated")
+		log.Warn("warning")
+	}
+	log.Info("other")
+	assert.NoError(t, closer.Close())
+
+	counts := map[string]int{}
+	for _, line := range readLines(t, path) {
+		for _, msg := range []string{`"msg":"repeated"`, `"msg":"warning"`, `"msg":"other"`} {
+			if strings.Contains(line, msg) {
+				counts[msg]++
+			}
+		}
+	}
+	// 3 first ones, then the 8th, 13th and 18th
+	assert.Equal(t, 6, counts[`"msg":"repeated"`])
+	assert.Equal(t, 20, counts[`"msg":"warning"`])
+	assert.Equal(t, 1, counts[`"msg":"other"`])
+}
@@ -0,0 +1,243 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock for CharacterService
+type MockCharacterService struct {
+	mock.Mock
+}
+
+func (m *MockCharacterService) Close(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockCharacterService) CreateCharacter(ctx context.Context, characterName string) (*domain.Character, bool, error) {
+	args := m.Called(characterName)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*domain.Character), args.Bool(1), args.Error(2)
+}
+
+func (m *MockCharacterService) GetCharacter(ctx context.Context, id string) (*domain.Character, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Character), args.Error(1)
+}
+
+func (m *MockCharacterService) ListCharacters(ctx context.Context, params domain.CharacterListParams) (*domain.CharacterPage, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CharacterPage), args.Error(1)
+}
+
+func (m *MockCharacterService) PurgeUnknownNames(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func newTestRouter(service *MockCharacterService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := httpadapter.NewCharacterHandler(service, logger)
+
+	router := gin.New()
+	router.GET("/characters", handler.ListCharacters)
+	router.POST("/characters", handler.CreateCharacter)
+	router.GET("/characters/:id", handler.GetCharacter)
+	return router
+}
+
+func decodeProblem(t *testing.T, recorder *httptest.ResponseRecorder) httpadapter.Problem {
+	t.Helper()
+	var problem httpadapter.Problem
+	if err := json.Unmarshal(recorder.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("response is not a problem document: %v", err)
+	}
+	return problem
+}
+
+func TestCharacterHandlerCreateCharacterStatus(t *testing.T) {
+	character := &domain.Character{ID: "1", Name: "Goku", Ki: domain.NewKi("60.000.000"), Race: "Saiyan"}
+
+	// Imported from the external API
+	service := new(MockCharacterService)
+	service.On("CreateCharacter", "Goku").Return(character, true, nil).Once()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/characters", strings.NewReader(`{"name":"Goku"}`))
+	request.Header.Set("Content-Type", "application/json")
+	newTestRouter(service).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "/characters/1", recorder.Header().Get("Location"))
+
+	// Already stored locally
+	service = new(MockCharacterService)
+	service.On("CreateCharacter", "Goku").Return(character, false, nil).Once()
+
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/characters", strings.NewReader(`{"name":"Goku"}`))
+	request.Header.Set("Content-Type", "application/json")
+	newTestRouter(service).ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Location"))
+}
+
+func TestCharacterHandlerCreateCharacterErrorStatus(t *testing.T) {
+	testCases := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+		expectedCode   string
+	}{
+		{"not found", fmt.Errorf("character 'Gokku' not found in external API: %w", domain.ErrNotFound), http.StatusNotFound, httpadapter.CodeNotFound},
+		{"validation", domain.NewValidationError("name", "must not be blank"), http.StatusUnprocessableEntity, httpadapter.CodeValidationFailed},
+		{"conflict", fmt.Errorf("failed to save character: %w", domain.ErrConflict), http.StatusConflict, httpadapter.CodeConflict},
+		{"upstream unavailable", fmt.Errorf("failed to fetch character from external API: %w", domain.ErrUpstreamUnavailable), http.StatusBadGateway, httpadapter.CodeUpstreamUnavailable},
+		{"upstream timeout", fmt.Errorf("failed to fetch character from external API: %w", domain.ErrUpstreamTimeout), http.StatusGatewayTimeout, httpadapter.CodeUpstreamTimeout},
+		{"unexpected", errors.New("DB save error"), http.StatusInternalServerError, httpadapter.CodeInternalError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service := new(MockCharacterService)
+			service.On("CreateCharacter", "Goku").Return(nil, false, tc.serviceErr).Once()
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodPost, "/characters", strings.NewReader(`{"name":"Goku"}`))
+			request.Header.Set("Content-Type", "application/json")
+			newTestRouter(service).ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+
+			problem := decodeProblem(t, recorder)
+			assert.Equal(t, tc.expectedStatus, problem.Status)
+			assert.Equal(t, tc.expectedCode, problem.Code)
+			assert.Equal(t, "/characters", problem.Instance)
+			assert.NotContains(t, problem.Detail, tc.serviceErr.Error()) // Internal wrapping must not leak
+			service.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCharacterHandlerCreateCharacterBindErrors(t *testing.T) {
+	service := new(MockCharacterService)
+	router := newTestRouter(service)
+
+	// Missing required field
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/characters", strings.NewReader(`{}`))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	problem := decodeProblem(t, recorder)
+	assert.Equal(t, httpadapter.CodeValidationFailed, problem.Code)
+	assert.Equal(t, []httpadapter.FieldError{{Field: "name", Message: "is required"}}, problem.Errors)
+
+	// Wrong field type
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/characters", strings.NewReader(`{"name": 42}`))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	problem = decodeProblem(t, recorder)
+	assert.Equal(t, "name", problem.Errors[0].Field)
+
+	// Malformed JSON
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/characters", strings.NewReader(`{"name":`))
+	request.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, httpadapter.CodeMalformedRequest, decodeProblem(t, recorder).Code)
+	service.AssertNotCalled(t, "CreateCharacter")
+}
+
+func TestCharacterHandlerGetCharacterNotFound(t *testing.T) {
+	service := new(MockCharacterService)
+	service.On("GetCharacter", "999").Return(nil, fmt.Errorf("character '999' not found: %w", domain.ErrNotFound)).Once()
+
+	recorder := httptest.NewRecorder()
+	newTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/characters/999", nil))
+
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	service.AssertExpectations(t)
+}
+
+func TestCharacterHandlerGetCharacterReportsAge(t *testing.T) {
+	character := &domain.Character{ID: "1", Name: "Goku", Cache: &domain.CacheInfo{Source: domain.CacheSourceLocal, AgeSeconds: 7200, Stale: true}}
+	service := new(MockCharacterService)
+	service.On("GetCharacter", "1").Return(character, nil).Once()
+
+	recorder := httptest.NewRecorder()
+	newTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/characters/1", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "7200", recorder.Header().Get("Age"))
+	var body struct {
+		Cache json.RawMessage `json:"cache"`
+	}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.JSONEq(t, `{"source":"local","age_seconds":7200,"stale":true}`, string(body.Cache))
+}
+
+func TestCharacterHandlerListCharactersInvalidLimit(t *testing.T) {
+	service := new(MockCharacterService)
+
+	recorder := httptest.NewRecorder()
+	newTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/characters?limit=abc", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, []httpadapter.FieldError{{Field: "limit", Message: "must be an integer"}}, decodeProblem(t, recorder).Errors)
+	service.AssertNotCalled(t, "ListCharacters")
+}
+
+func TestCharacterHandlerListCharactersByKi(t *testing.T) {
+	service := new(MockCharacterService)
+	service.On("ListCharacters", mock.MatchedBy(func(params domain.CharacterListParams) bool {
+		return params.SortBy == domain.CharacterSortByKi && params.Descending &&
+			params.MinKi.String() == "1.000.000" && params.MaxKi.String() == "2 Googolplex"
+	})).Return(&domain.CharacterPage{Items: []*domain.Character{}}, nil).Once()
+
+	recorder := httptest.NewRecorder()
+	newTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/characters?sort=-ki&min_ki=1.000.000&max_ki=2+Googolplex", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	service.AssertExpectations(t)
+
+	// Bounds that are not power levels are rejected before listing
+	recorder = httptest.NewRecorder()
+	newTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/characters?min_ki=unknown", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, "min_ki", decodeProblem(t, recorder).Errors[0].Field)
+	service.AssertNumberOfCalls(t, "ListCharacters", 1)
+}
@@ -0,0 +1,126 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthHandlerReadinessFlipsOnShutdown(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := httpadapter.NewHealthHandler(logger)
+
+	router := gin.New()
+	router.GET("/healthz", handler.Liveness)
+	router.GET("/readyz", handler.Readiness)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	handler.SetShuttingDown()
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+
+	// Liveness is unaffected, the process is still healthy while draining
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+type stubChecker struct {
+	name string
+	err  error
+}
+
+func (s stubChecker) Name() string                    { return s.name }
+func (s stubChecker) Check(ctx context.Context) error { return s.err }
+
+type readinessBody struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status    string  `json:"status"`
+		Critical  bool    `json:"critical"`
+		LatencyMs float64 `json:"latency_ms"`
+		Error     string  `json:"error"`
+	} `json:"checks"`
+}
+
+func TestHealthHandlerReadinessBreakdown(t *testing.T) {
+	testCases := []struct {
+		name           string
+		checks         []httpadapter.DependencyCheck
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name: "all up",
+			checks: []httpadapter.DependencyCheck{
+				{Checker: stubChecker{name: "database"}, Critical: true},
+				{Checker: stubChecker{name: "dragonball_api"}, Critical: false},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "ready",
+		},
+		{
+			name: "optional dependency down",
+			checks: []httpadapter.DependencyCheck{
+				{Checker: stubChecker{name: "database"}, Critical: true},
+				{Checker: stubChecker{name: "dragonball_api", err: errors.New("circuit breaker is open")}, Critical: false},
+			},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "degraded",
+		},
+		{
+			name: "critical dependency down",
+			checks: []httpadapter.DependencyCheck{
+				{Checker: stubChecker{name: "database", err: errors.New("connection refused")}, Critical: true},
+				{Checker: stubChecker{name: "dragonball_api"}, Critical: false},
+			},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "not_ready",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			handler := httpadapter.NewHealthHandler(logger, tc.checks...)
+
+			router := gin.New()
+			router.GET("/readyz", handler.Readiness)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+
+			var body readinessBody
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, tc.expectedStatus, body.Status)
+			assert.Len(t, body.Checks, len(tc.checks))
+			for _, check := range tc.checks {
+				result := body.Checks[check.Checker.Name()]
+				assert.Equal(t, check.Critical, result.Critical)
+				if check.Checker.(stubChecker).err != nil {
+					assert.Equal(t, "down", result.Status)
+					assert.NotEmpty(t, result.Error)
+				} else {
+					assert.Equal(t, "up", result.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package http_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	level := new(slog.LevelVar)
+	handler := httpadapter.NewLogLevelHandler(level, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+
+	router := gin.New()
+	router.GET("/admin/log-level", handler.GetLevel)
+	router.PUT("/admin/log-level", handler.SetLevel)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/log-level", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"INFO"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"level":"DEBUG"}`, w.Body.String())
+	assert.Equal(t, slog.LevelDebug, level.Level())
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/log-level", strings.NewReader(`{"level":"verbose"}`)))
+	problem := decodeProblem(t, w)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, httpadapter.CodeValidationFailed, problem.Code)
+	assert.Equal(t, slog.LevelDebug, level.Level(), "a rejected level leaves the current one")
+}
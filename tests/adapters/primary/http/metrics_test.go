@@ -0,0 +1,47 @@
+package http_test
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsLabelsRequestsByRouteAndStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	registry := prometheus.NewRegistry()
+
+	mockService := new(MockCharacterService)
+	mockService.On("GetCharacter", "1").Return(&domain.Character{ID: "1", Name: "Goku"}, nil)
+	mockService.On("GetCharacter", "999").Return(nil, domain.ErrNotFound)
+	handler := httpadapter.NewCharacterHandler(mockService, logger)
+
+	router := gin.New()
+	router.Use(httpadapter.Metrics(registry))
+	router.GET("/characters/:id", handler.GetCharacter)
+
+	for _, path := range []string{"/characters/1", "/characters/1", "/characters/999", "/unknown/path"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	// Raw paths never show up as labels, unmatched ones share a single series
+	expected := `
+# HELP characters_api_http_requests_total HTTP requests by method, route and status code.
+# TYPE characters_api_http_requests_total counter
+characters_api_http_requests_total{method="GET",route="/characters/:id",status="200"} 2
+characters_api_http_requests_total{method="GET",route="/characters/:id",status="404"} 1
+characters_api_http_requests_total{method="GET",route="unmatched",status="404"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "characters_api_http_requests_total"))
+	assert.Equal(t, 3, testutil.CollectAndCount(registry, "characters_api_http_request_duration_seconds"))
+}
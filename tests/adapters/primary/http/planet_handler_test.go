@@ -0,0 +1,116 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock for PlanetService
+type MockPlanetService struct {
+	mock.Mock
+}
+
+func (m *MockPlanetService) Close(ctx context.Context) error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockPlanetService) ListPlanets(ctx context.Context) ([]*domain.Planet, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Planet), args.Error(1)
+}
+
+func (m *MockPlanetService) GetPlanet(ctx context.Context, id string) (*domain.Planet, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Planet), args.Error(1)
+}
+
+func (m *MockPlanetService) ListPlanetCharacters(ctx context.Context, id string, params domain.CharacterListParams) (*domain.CharacterPage, error) {
+	args := m.Called(id, params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CharacterPage), args.Error(1)
+}
+
+func newPlanetTestRouter(service *MockPlanetService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	handler := httpadapter.NewPlanetHandler(service, logger)
+
+	router := gin.New()
+	router.GET("/planets", handler.ListPlanets)
+	router.GET("/planets/:id", handler.GetPlanet)
+	router.GET("/planets/:id/characters", handler.ListPlanetCharacters)
+	return router
+}
+
+func TestPlanetHandlerListPlanets(t *testing.T) {
+	service := new(MockPlanetService)
+	service.On("ListPlanets").Return([]*domain.Planet{{ID: "1", Name: "Namek", IsDestroyed: true}}, nil).Once()
+
+	recorder := httptest.NewRecorder()
+	newPlanetTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/planets", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var body struct {
+		Items []domain.Planet `json:"items"`
+	}
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+	assert.Len(t, body.Items, 1)
+	assert.Equal(t, "Namek", body.Items[0].Name)
+}
+
+func TestPlanetHandlerGetPlanet(t *testing.T) {
+	planet := &domain.Planet{ID: "1", Name: "Namek", Cache: &domain.CacheInfo{Source: domain.CacheSourceLocal, AgeSeconds: 60}}
+	service := new(MockPlanetService)
+	service.On("GetPlanet", "1").Return(planet, nil).Once()
+	service.On("GetPlanet", "999").Return(nil, fmt.Errorf("planet '999' not found: %w", domain.ErrNotFound)).Once()
+
+	recorder := httptest.NewRecorder()
+	newPlanetTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/planets/1", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "60", recorder.Header().Get("Age"))
+
+	recorder = httptest.NewRecorder()
+	newPlanetTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/planets/999", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	service.AssertExpectations(t)
+}
+
+func TestPlanetHandlerListPlanetCharacters(t *testing.T) {
+	service := new(MockPlanetService)
+	service.On("ListPlanetCharacters", "3", mock.MatchedBy(func(params domain.CharacterListParams) bool {
+		return params.Race == "Saiyan" && params.Limit == 5
+	})).Return(&domain.CharacterPage{Items: []*domain.Character{{ID: "1", Name: "Goku"}}}, nil).Once()
+
+	recorder := httptest.NewRecorder()
+	newPlanetTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/planets/3/characters?race=Saiyan&limit=5", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	service.AssertExpectations(t)
+
+	// Invalid list parameters are rejected before listing
+	recorder = httptest.NewRecorder()
+	newPlanetTestRouter(service).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/planets/3/characters?limit=abc", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Equal(t, "limit", decodeProblem(t, recorder).Errors[0].Field)
+	service.AssertNumberOfCalls(t, "ListPlanetCharacters", 1)
+}
@@ -0,0 +1,53 @@
+package http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend.go.characters.api/internal/adapters/logger"
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(httpadapter.RequestID())
+	router.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, logger.RequestIDFromContext(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "accepts incoming ID", incoming: "req-123", keep: true},
+		{name: "generates missing ID", incoming: ""},
+		{name: "replaces ID with control characters", incoming: "req\n123"},
+		{name: "replaces oversized ID", incoming: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if tt.incoming != "" {
+				req.Header.Set(httpadapter.RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(httpadapter.RequestIDHeader)
+			assert.Equal(t, requestID, w.Body.String(), "the context carries the echoed ID")
+			if tt.keep {
+				assert.Equal(t, tt.incoming, requestID)
+			} else {
+				assert.Len(t, requestID, 32)
+				assert.NotEqual(t, tt.incoming, requestID)
+			}
+		})
+	}
+}
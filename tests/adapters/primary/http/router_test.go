@@ -0,0 +1,212 @@
+package http_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend.go.characters.api/internal/adapters/logger"
+	httpadapter "backend.go.characters.api/internal/adapters/primary/http"
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// logRecords decodes the JSON log lines written to buf.
+func logRecords(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line is not JSON: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// testAdminToken is the admin token of the routers under test.
+const testAdminToken = "s3cret"
+
+func newRouterUnderTest(service *MockCharacterService, buf *bytes.Buffer) *gin.Engine {
+	return newRouterWithAdminToken(service, buf, testAdminToken)
+}
+
+func newRouterWithAdminToken(service *MockCharacterService, buf *bytes.Buffer, adminToken string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := slog.New(logger.NewContextHandler(slog.NewJSONHandler(buf, nil)))
+	return httpadapter.NewRouter(httpadapter.RouterConfig{
+		Logger:              log,
+		MetricsRegistry:     prometheus.NewRegistry(),
+		CharacterHandler:    httpadapter.NewCharacterHandler(service, log),
+		PlanetHandler:       httpadapter.NewPlanetHandler(new(MockPlanetService), log),
+		HealthHandler:       httpadapter.NewHealthHandler(log),
+		LogLevelHandler:     httpadapter.NewLogLevelHandler(new(slog.LevelVar), log),
+		UnknownNamesHandler: httpadapter.NewUnknownNamesHandler(service, log),
+		AdminToken:          adminToken,
+		AccessLogSkipPaths:  []string{"/healthz"},
+	})
+}
+
+// adminRequest builds a request to an admin route carrying token.
+func adminRequest(method, path, token string, body io.Reader) *http.Request {
+	req := httptest.NewRequest(method, path, body)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestRouterAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	mockService := new(MockCharacterService)
+	mockService.On("GetCharacter", "1").Return(&domain.Character{ID: "1", Name: "Goku"}, nil)
+	router := newRouterUnderTest(mockService, &buf)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Empty(t, buf.String(), "skipped paths are not logged")
+
+	req := httptest.NewRequest(http.MethodGet, "/characters/1", nil)
+	req.Header.Set(httpadapter.RequestIDHeader, "req-123")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var access map[string]any
+	for _, record := range logRecords(t, &buf) {
+		if record["msg"] == "HTTP request" {
+			access = record
+		}
+	}
+	if assert.NotNil(t, access) {
+		assert.Equal(t, "INFO", access["level"])
+		assert.Equal(t, "GET", access["method"])
+		assert.Equal(t, "/characters/:id", access["route"])
+		assert.Equal(t, float64(http.StatusOK), access["status"])
+		assert.Equal(t, float64(w.Body.Len()), access["bytes"])
+		assert.Equal(t, "192.0.2.1", access["client_ip"])
+		assert.Equal(t, "req-123", access["request_id"])
+		assert.Contains(t, access, "latency")
+	}
+}
+
+func TestRouterRecoversFromPanics(t *testing.T) {
+	var buf bytes.Buffer
+	mockService := new(MockCharacterService)
+	mockService.On("GetCharacter", "boom").Run(func(mock.Arguments) { panic("kaboom") })
+	router := newRouterUnderTest(mockService, &buf)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/characters/boom", nil))
+
+	problem := decodeProblem(t, w)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, httpadapter.CodeInternalError, problem.Code)
+	assert.NotContains(t, w.Body.String(), "kaboom")
+
+	records := logRecords(t, &buf)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "Recovered from panic", records[0]["msg"])
+		assert.Equal(t, "kaboom", records[0]["panic"])
+		assert.Contains(t, records[0]["stack"], "runtime/debug.Stack")
+		assert.Equal(t, "HTTP request", records[1]["msg"])
+		assert.Equal(t, "ERROR", records[1]["level"])
+		assert.Equal(t, records[0]["request_id"], records[1]["request_id"])
+	}
+}
+
+func TestRouterPropagatesAbortedRequests(t *testing.T) {
+	var buf bytes.Buffer
+	mockService := new(MockCharacterService)
+	mockService.On("GetCharacter", "gone").Run(func(mock.Arguments) { panic(http.ErrAbortHandler) })
+	router := newRouterUnderTest(mockService, &buf)
+
+	// net/http needs the panic to abort the response without logging it
+	w := httptest.NewRecorder()
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/characters/gone", nil))
+	})
+	assert.Empty(t, w.Body.String())
+
+	// The request is still logged before the panic goes on
+	records := logRecords(t, &buf)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, "Request aborted", records[0]["msg"])
+		assert.Equal(t, "HTTP request", records[1]["msg"])
+	}
+}
+
+func TestRouterAnswersUnknownRoutesWithProblem(t *testing.T) {
+	var buf bytes.Buffer
+	router := newRouterUnderTest(new(MockCharacterService), &buf)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/nope", nil))
+
+	problem := decodeProblem(t, w)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, httpadapter.CodeNotFound, problem.Code)
+	assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+}
+
+func TestRouterPurgesUnknownNames(t *testing.T) {
+	var buf bytes.Buffer
+	mockService := new(MockCharacterService)
+	mockService.On("PurgeUnknownNames").Return(int64(3), nil).Once()
+	router := newRouterUnderTest(mockService, &buf)
+
+	// The purge is an admin route
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodDelete, "/admin/unknown-names", "", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodDelete, "/admin/unknown-names", testAdminToken, nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"purged":3}`, w.Body.String())
+
+	mockService.On("PurgeUnknownNames").Return(int64(0), errors.New("connection reset")).Once()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodDelete, "/admin/unknown-names", testAdminToken, nil))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, httpadapter.CodeInternalError, decodeProblem(t, w).Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRouterRequiresAdminToken(t *testing.T) {
+	var buf bytes.Buffer
+	router := newRouterUnderTest(new(MockCharacterService), &buf)
+
+	// Missing and wrong tokens are rejected
+	for _, token := range []string{"", "wrong"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, adminRequest(http.MethodPut, "/admin/log-level", token, strings.NewReader(`{"level":"debug"}`)))
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, httpadapter.CodeUnauthorized, decodeProblem(t, w).Code)
+		assert.Equal(t, `Bearer realm="admin"`, w.Header().Get("WWW-Authenticate"))
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/log-level", testAdminToken, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Without a token configured the admin routes are not served
+	router = newRouterWithAdminToken(new(MockCharacterService), &buf, "")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, adminRequest(http.MethodGet, "/admin/log-level", "", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,84 @@
+package domain_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKi(t *testing.T) {
+	tests := []struct {
+		text        string
+		magnitude   string
+		significand string
+	}{
+		{"9000", "3", "9"},
+		{"60.000.000", "7", "6"},
+		{"60,000,000", "7", "6"},
+		{"1,234.5", "3", "1.2345"},
+		{"1.234,5", "3", "1.2345"},
+		{"2.5 Billion", "9", "2.5"},
+		{"1.500 Billion", "12", "1.5"},
+		{"24 billion", "10", "2.4"},
+		{"90 Septillion", "25", "9"},
+		{"19.84 Septillion", "25", "1.984"},
+		{"11 Googol", "101", "1.1"},
+		{"2 Googolplex", "10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000", "2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			ki, err := domain.ParseKi(tt.text)
+			assert.NoError(t, err)
+			assert.True(t, ki.Known())
+			assert.Equal(t, tt.text, ki.String())
+			assert.Equal(t, tt.magnitude, ki.Magnitude().String())
+			assert.Equal(t, tt.significand, ki.Significand())
+		})
+	}
+
+	zero, err := domain.ParseKi("0")
+	assert.NoError(t, err)
+	assert.True(t, zero.IsZero())
+	assert.Nil(t, zero.Magnitude())
+
+	for _, text := range []string{"", "unknown", "12 Bazillion", "1.2.3", "0.5", "1,000.000,5"} {
+		_, err := domain.ParseKi(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func TestNewKiKeepsUnknownText(t *testing.T) {
+	ki := domain.NewKi("unknown")
+	assert.False(t, ki.Known())
+	assert.Equal(t, "unknown", ki.String())
+
+	data, err := json.Marshal(struct {
+		Ki domain.Ki `json:"ki"`
+	}{domain.NewKi("2.5 Billion")})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"ki":"2.5 Billion"}`, string(data))
+
+	var decoded struct {
+		Ki domain.Ki `json:"ki"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 0, decoded.Ki.Cmp(domain.NewKi("2.500.000.000")))
+}
+
+func TestKiCmp(t *testing.T) {
+	ordered := []string{"unknown", "0", "9000", "60.000.000", "2.5 Billion", "24 Billion", "90 Septillion", "1 Googol", "1 Googolplex"}
+	for i := range ordered {
+		for j := range ordered {
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			assert.Equal(t, expected, domain.NewKi(ordered[i]).Cmp(domain.NewKi(ordered[j])), "%s vs %s", ordered[i], ordered[j])
+		}
+	}
+	assert.Equal(t, 0, domain.NewKi("1,000 Million").Cmp(domain.NewKi("1 Billion")))
+}
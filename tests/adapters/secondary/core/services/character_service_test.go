@@ -1,14 +1,28 @@
 package services_test
 
 import (
+	"context"
 	"errors"
 	"log/slog"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"backend.go.characters.api/internal/adapters/secondary/rediscache"
 	"backend.go.characters.api/internal/core/domain"
 	"backend.go.characters.api/internal/core/services"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -18,12 +32,12 @@ type MockCharacterRepository struct {
 	mock.Mock
 }
 
-func (m *MockCharacterRepository) SaveCharacter(character *domain.Character) error {
+func (m *MockCharacterRepository) SaveCharacter(ctx context.Context, character *domain.Character) error {
 	args := m.Called(character)
 	return args.Error(0)
 }
 
-func (m *MockCharacterRepository) FindCharacterByName(name string) (*domain.Character, error) {
+func (m *MockCharacterRepository) FindCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -31,12 +45,28 @@ func (m *MockCharacterRepository) FindCharacterByName(name string) (*domain.Char
 	return args.Get(0).(*domain.Character), args.Error(1)
 }
 
+func (m *MockCharacterRepository) FindCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Character), args.Error(1)
+}
+
+func (m *MockCharacterRepository) ListCharacters(ctx context.Context, params domain.CharacterListParams) (*domain.CharacterPage, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CharacterPage), args.Error(1)
+}
+
 // Mock for DragonBallAPIClient
 type MockDragonBallAPIClient struct {
 	mock.Mock
 }
 
-func (m *MockDragonBallAPIClient) FindCharacterByName(name string) (*domain.Character, error) {
+func (m *MockDragonBallAPIClient) FindCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
 	args := m.Called(name)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -44,7 +74,7 @@ func (m *MockDragonBallAPIClient) FindCharacterByName(name string) (*domain.Char
 	return args.Get(0).(*domain.Character), args.Error(1)
 }
 
-func (m *MockDragonBallAPIClient) FindCharacterByID(id string) (*domain.Character, error) {
+func (m *MockDragonBallAPIClient) FindCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
 	args := m.Called(id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -52,12 +82,66 @@ func (m *MockDragonBallAPIClient) FindCharacterByID(id string) (*domain.Characte
 	return args.Get(0).(*domain.Character), args.Error(1)
 }
 
+func (m *MockDragonBallAPIClient) ListPlanets(ctx context.Context) ([]*domain.Planet, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Planet), args.Error(1)
+}
+
+func (m *MockDragonBallAPIClient) FindPlanetByID(ctx context.Context, id string) (*domain.Planet, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Planet), args.Error(1)
+}
+
+func (m *MockDragonBallAPIClient) ListPlanetCharacterIDs(ctx context.Context, id string) ([]string, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// Mock for CharacterMetrics
+type MockCharacterMetrics struct {
+	mock.Mock
+}
+
+func (m *MockCharacterMetrics) RecordLookup(operation string, outcome domain.LookupOutcome) {
+	m.Called(operation, outcome)
+}
+
+// Mock for UnknownNameRepository
+type MockUnknownNameRepository struct {
+	mock.Mock
+}
+
+func (m *MockUnknownNameRepository) IsUnknownName(ctx context.Context, name string) (bool, error) {
+	args := m.Called(name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUnknownNameRepository) SaveUnknownName(ctx context.Context, name string, ttl time.Duration) error {
+	args := m.Called(name, ttl)
+	return args.Error(0)
+}
+
+func (m *MockUnknownNameRepository) PurgeUnknownNames(ctx context.Context) (int64, error) {
+	args := m.Called()
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestCharacterService_CreateCharacter_FromDB(t *testing.T) {
 	mockRepo := new(MockCharacterRepository)
 	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	charService := services.NewCharacterService(mockRepo, mockAPIClient, logger)
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
 
 	expectedCharacter := &domain.Character{
 		ID:   "123",
@@ -66,25 +150,29 @@ func TestCharacterService_CreateCharacter_FromDB(t *testing.T) {
 
 	// Expect FindCharacterByName to return an existing character
 	mockRepo.On("FindCharacterByName", "Goku").Return(expectedCharacter, nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupLocalHit).Once()
 
-	character, err := charService.CreateCharacter("Goku")
+	character, created, err := charService.CreateCharacter(context.Background(), "Goku")
 	assert.NoError(t, err)
+	assert.False(t, created)
 	assert.Equal(t, expectedCharacter, character)
 	mockRepo.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
 	mockAPIClient.AssertNotCalled(t, "FindCharacterByName") // Should not call API if found in DB
 }
 
 func TestCharacterService_CreateCharacter_FromAPI(t *testing.T) {
 	mockRepo := new(MockCharacterRepository)
 	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	charService := services.NewCharacterService(mockRepo, mockAPIClient, logger)
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
 
 	apiCharacter := &domain.Character{
 		ID:   "456",
 		Name: "Vegeta",
-		Ki:   "8000",
+		Ki:   domain.NewKi("8000"),
 		Race: "Saiyan",
 	}
 
@@ -94,9 +182,11 @@ func TestCharacterService_CreateCharacter_FromAPI(t *testing.T) {
 	mockAPIClient.On("FindCharacterByName", "Vegeta").Return(apiCharacter, nil).Once()
 	// Expect SaveCharacter to be called
 	mockRepo.On("SaveCharacter", mock.AnythingOfType("*domain.Character")).Return(nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupUpstreamFetch).Once()
 
-	character, err := charService.CreateCharacter("Vegeta")
+	character, created, err := charService.CreateCharacter(context.Background(), "Vegeta")
 	assert.NoError(t, err)
+	assert.True(t, created)
 	assert.NotNil(t, character)
 	assert.Equal(t, apiCharacter.ID, character.ID)
 	assert.Equal(t, apiCharacter.Name, character.Name)
@@ -105,35 +195,136 @@ func TestCharacterService_CreateCharacter_FromAPI(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 	mockAPIClient.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestCharacterService_CreateCharacter_TracesLookupOutcome(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	mockRepo.On("FindCharacterByName", "Krillin").Return(nil, nil).Once()
+	mockAPIClient.On("FindCharacterByName", "Krillin").Return(nil, errors.New("API error")).Once()
+
+	_, _, err := charService.CreateCharacter(context.Background(), "Krillin")
+	assert.Error(t, err)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, "characterService.CreateCharacter", spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Contains(t, spans[0].Attributes, attribute.String("character.name", "Krillin"))
+	}
+
+	mockRepo.On("FindCharacterByName", "Goku").Return(&domain.Character{ID: "1", Name: "Goku"}, nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupLocalHit).Once()
+	exporter.Reset()
+
+	_, _, err = charService.CreateCharacter(context.Background(), "Goku")
+	assert.NoError(t, err)
+
+	spans = exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, codes.Unset, spans[0].Status.Code)
+		assert.Contains(t, spans[0].Attributes, attribute.String("character.lookup_outcome", "local_hit"))
+	}
 }
 
 func TestCharacterService_CreateCharacter_APIError(t *testing.T) {
 	mockRepo := new(MockCharacterRepository)
 	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	charService := services.NewCharacterService(mockRepo, mockAPIClient, logger)
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
 
 	// Expect FindCharacterByName from DB to return nil (not found)
 	mockRepo.On("FindCharacterByName", "Krillin").Return(nil, nil).Once()
 	// Expect FindCharacterByName from API to return an error
 	mockAPIClient.On("FindCharacterByName", "Krillin").Return(nil, errors.New("API error")).Once()
 
-	character, err := charService.CreateCharacter("Krillin")
+	character, _, err := charService.CreateCharacter(context.Background(), "Krillin")
 	assert.Error(t, err)
 	assert.Nil(t, character)
 	assert.Contains(t, err.Error(), "failed to fetch character from external API")
+	assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
 	mockRepo.AssertExpectations(t)
 	mockAPIClient.AssertExpectations(t)
 	mockRepo.AssertNotCalled(t, "SaveCharacter")
 }
 
+func TestCharacterService_CreateCharacter_NotFoundInAPI(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	mockRepo.On("FindCharacterByName", "Gokku").Return(nil, nil).Once()
+	mockAPIClient.On("FindCharacterByName", "Gokku").Return(nil, nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupNotFound).Once()
+
+	character, _, err := charService.CreateCharacter(context.Background(), "Gokku")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, character)
+	mockRepo.AssertNotCalled(t, "SaveCharacter")
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestCharacterService_CreateCharacter_BlankName(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	character, _, err := charService.CreateCharacter(context.Background(), "   ")
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Nil(t, character)
+	mockRepo.AssertNotCalled(t, "FindCharacterByName")
+}
+
+func TestCharacterService_CreateCharacter_OverlongName(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	mockUnknownNames := new(MockUnknownNameRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, mockUnknownNames, mockMetrics, domain.FreshnessPolicy{NotFoundTTL: time.Minute}, logger)
+
+	// Rejected before any lookup, so it never reaches the upstream nor the unknown names
+	character, _, err := charService.CreateCharacter(context.Background(), strings.Repeat("ü", domain.MaxCharacterNameLength+1))
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Nil(t, character)
+	mockRepo.AssertNotCalled(t, "FindCharacterByName", mock.Anything)
+	mockAPIClient.AssertNotCalled(t, "FindCharacterByName", mock.Anything)
+	mockUnknownNames.AssertNotCalled(t, "SaveUnknownName", mock.Anything, mock.Anything)
+
+	// A name filling the column is looked up as usual
+	name := strings.Repeat("ü", domain.MaxCharacterNameLength)
+	mockRepo.On("FindCharacterByName", name).Return(&domain.Character{ID: "1", Name: name, UpdatedAt: time.Now()}, nil).Once()
+	mockMetrics.On("RecordLookup", mock.Anything, mock.Anything)
+	_, _, err = charService.CreateCharacter(context.Background(), name)
+	assert.NoError(t, err)
+}
+
 func TestCharacterService_CreateCharacter_SaveError(t *testing.T) {
 	mockRepo := new(MockCharacterRepository)
 	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
 
-	charService := services.NewCharacterService(mockRepo, mockAPIClient, logger)
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
 
 	apiCharacter := &domain.Character{
 		ID:   "789",
@@ -147,10 +338,442 @@ func TestCharacterService_CreateCharacter_SaveError(t *testing.T) {
 	// Expect SaveCharacter to return an error
 	mockRepo.On("SaveCharacter", mock.AnythingOfType("*domain.Character")).Return(errors.New("DB save error")).Once()
 
-	character, err := charService.CreateCharacter("Piccolo")
+	character, _, err := charService.CreateCharacter(context.Background(), "Piccolo")
 	assert.Error(t, err)
 	assert.Nil(t, character)
 	assert.Contains(t, err.Error(), "failed to save character")
 	mockRepo.AssertExpectations(t)
 	mockAPIClient.AssertExpectations(t)
 }
+
+func TestCharacterService_GetCharacter_FromDB(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	expectedCharacter := &domain.Character{
+		ID:   "1",
+		Name: "Goku",
+	}
+
+	// Expect FindCharacterByID to return an existing character
+	mockRepo.On("FindCharacterByID", "1").Return(expectedCharacter, nil).Once()
+	mockMetrics.On("RecordLookup", "get", domain.LookupLocalHit).Once()
+
+	character, err := charService.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCharacter, character)
+	mockRepo.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+	mockAPIClient.AssertNotCalled(t, "FindCharacterByID") // Should not call API if found in DB
+}
+
+func TestCharacterService_GetCharacter_FromAPI(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	apiCharacter := &domain.Character{
+		ID:   "4",
+		Name: "Vegeta",
+		Ki:   domain.NewKi("54 Trillion"),
+		Race: "Saiyan",
+	}
+
+	// Expect FindCharacterByID from DB to return nil (not found)
+	mockRepo.On("FindCharacterByID", "4").Return(nil, nil).Once()
+	// Expect FindCharacterByID from API to return a character
+	mockAPIClient.On("FindCharacterByID", "4").Return(apiCharacter, nil).Once()
+	// Expect SaveCharacter to be called
+	mockRepo.On("SaveCharacter", mock.AnythingOfType("*domain.Character")).Return(nil).Once()
+	mockMetrics.On("RecordLookup", "get", domain.LookupUpstreamFetch).Once()
+
+	character, err := charService.GetCharacter(context.Background(), "4")
+	assert.NoError(t, err)
+	assert.NotNil(t, character)
+	assert.Equal(t, apiCharacter.ID, character.ID)
+	assert.Equal(t, apiCharacter.Name, character.Name)
+
+	mockRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestCharacterService_GetCharacter_NotFound(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	// Neither the DB nor the API know the ID
+	mockRepo.On("FindCharacterByID", "999").Return(nil, nil).Once()
+	mockAPIClient.On("FindCharacterByID", "999").Return(nil, nil).Once()
+	mockMetrics.On("RecordLookup", "get", domain.LookupNotFound).Once()
+
+	character, err := charService.GetCharacter(context.Background(), "999")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, character)
+	mockRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "SaveCharacter")
+}
+
+func TestCharacterService_GetCharacter_ServesStaleAndRefreshesInBackground(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, freshness, logger)
+
+	staleCharacter := &domain.Character{ID: "1", Name: "Goku", Ki: domain.NewKi("60.000.000"), UpdatedAt: time.Now().Add(-2 * time.Hour)}
+	mockRepo.On("FindCharacterByID", "1").Return(staleCharacter, nil).Once()
+	mockMetrics.On("RecordLookup", "get", domain.LookupStaleHit).Once()
+
+	// The refresh runs after the stale character was served
+	release := make(chan struct{})
+	refreshed := make(chan struct{})
+	mockAPIClient.On("FindCharacterByID", "1").
+		Run(func(mock.Arguments) { <-release }).
+		Return(&domain.Character{ID: "1", Name: "Goku", Ki: domain.NewKi("90 Septillion")}, nil).Once()
+	mockRepo.On("SaveCharacter", mock.MatchedBy(func(c *domain.Character) bool { return c.Ki.String() == "90 Septillion" })).
+		Run(func(mock.Arguments) { close(refreshed) }).
+		Return(nil).Once()
+
+	character, err := charService.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "60.000.000", character.Ki.String())
+	if assert.NotNil(t, character.Cache) {
+		assert.Equal(t, domain.CacheSourceLocal, character.Cache.Source)
+		assert.True(t, character.Cache.Stale)
+		assert.GreaterOrEqual(t, character.Cache.AgeSeconds, int64(7200))
+	}
+
+	close(release)
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale character was not refreshed in the background")
+	}
+	mockRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestCharacterService_Close_WaitsForBackgroundRefreshes(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, freshness, logger)
+
+	staleCharacter := func() *domain.Character {
+		return &domain.Character{ID: "1", Name: "Goku", UpdatedAt: time.Now().Add(-2 * time.Hour)}
+	}
+	mockRepo.On("FindCharacterByID", "1").Return(staleCharacter(), nil).Once()
+	mockMetrics.On("RecordLookup", "get", domain.LookupStaleHit).Twice()
+
+	release := make(chan struct{})
+	mockAPIClient.On("FindCharacterByID", "1").
+		Run(func(mock.Arguments) { <-release }).
+		Return(&domain.Character{ID: "1", Name: "Goku"}, nil).Once()
+	mockRepo.On("SaveCharacter", mock.Anything).Return(nil).Once()
+
+	_, err := charService.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+
+	// The refresh is still running, so Close gives up with the context
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, charService.Close(ctx), context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, charService.Close(context.Background()))
+	mockRepo.AssertExpectations(t)
+
+	// Once closed, stale characters are served without starting a refresh
+	mockRepo.On("FindCharacterByID", "1").Return(staleCharacter(), nil).Once()
+	_, err = charService.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.NoError(t, charService.Close(context.Background()))
+	mockAPIClient.AssertNumberOfCalls(t, "FindCharacterByID", 1)
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestCharacterService_GetCharacter_RefreshesExpired(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, freshness, logger)
+
+	expiredCharacter := &domain.Character{ID: "1", Name: "Goku", Ki: domain.NewKi("60.000.000"), UpdatedAt: time.Now().Add(-48 * time.Hour)}
+	mockRepo.On("FindCharacterByID", "1").Return(expiredCharacter, nil).Once()
+	mockAPIClient.On("FindCharacterByID", "1").Return(&domain.Character{ID: "1", Name: "Goku", Ki: domain.NewKi("90 Septillion")}, nil).Once()
+	mockRepo.On("SaveCharacter", mock.AnythingOfType("*domain.Character")).Return(nil).Once()
+	mockMetrics.On("RecordLookup", "get", domain.LookupRefreshed).Once()
+
+	character, err := charService.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "90 Septillion", character.Ki.String())
+	assert.Equal(t, &domain.CacheInfo{Source: domain.CacheSourceUpstream}, character.Cache)
+	mockRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+
+	// A failed refresh is not hidden behind the expired copy
+	mockRepo.On("FindCharacterByID", "1").Return(expiredCharacter, nil).Once()
+	mockAPIClient.On("FindCharacterByID", "1").Return(nil, domain.ErrUpstreamTimeout).Once()
+
+	character, err = charService.GetCharacter(context.Background(), "1")
+	assert.ErrorIs(t, err, domain.ErrUpstreamTimeout)
+	assert.Nil(t, character)
+}
+
+func TestCharacterService_GetCharacter_ServesFreshWithoutRefresh(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, freshness, logger)
+
+	freshCharacter := &domain.Character{ID: "1", Name: "Goku", UpdatedAt: time.Now().Add(-time.Minute)}
+	mockRepo.On("FindCharacterByID", "1").Return(freshCharacter, nil).Once()
+	mockMetrics.On("RecordLookup", "get", domain.LookupLocalHit).Once()
+
+	character, err := charService.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+	if assert.NotNil(t, character.Cache) {
+		assert.False(t, character.Cache.Stale)
+		assert.Equal(t, domain.CacheSourceLocal, character.Cache.Source)
+	}
+	mockAPIClient.AssertNotCalled(t, "FindCharacterByID", "1")
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestCharacterService_SharesLookupsThroughCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	cache := rediscache.NewCharacterCache(client, logger, rediscache.Options{})
+
+	// The first replica imports the character and shares it
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	firstReplica := services.NewCharacterService(mockRepo, mockAPIClient, cache, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	mockRepo.On("FindCharacterByName", "Goku").Return(nil, nil).Once()
+	mockAPIClient.On("FindCharacterByName", "Goku").Return(&domain.Character{ID: "1", Name: "Goku", Ki: domain.NewKi("60.000.000"), Race: "Saiyan"}, nil).Once()
+	mockRepo.On("SaveCharacter", mock.AnythingOfType("*domain.Character")).Return(nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupUpstreamFetch).Once()
+
+	_, created, err := firstReplica.CreateCharacter(context.Background(), "Goku")
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	// The second replica finds it in the cache, by name and by ID
+	otherRepo := new(MockCharacterRepository)
+	otherAPIClient := new(MockDragonBallAPIClient)
+	otherMetrics := new(MockCharacterMetrics)
+	secondReplica := services.NewCharacterService(otherRepo, otherAPIClient, cache, nil, otherMetrics, domain.FreshnessPolicy{}, logger)
+	otherMetrics.On("RecordLookup", "create", domain.LookupCacheHit).Once()
+	otherMetrics.On("RecordLookup", "get", domain.LookupCacheHit).Once()
+
+	character, created, err := secondReplica.CreateCharacter(context.Background(), "goku")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "60.000.000", character.Ki.String())
+	assert.Equal(t, domain.CacheSourceCache, character.Cache.Source)
+
+	character, err = secondReplica.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", character.Name)
+
+	otherRepo.AssertNotCalled(t, "FindCharacterByName", mock.Anything)
+	otherRepo.AssertNotCalled(t, "FindCharacterByID", mock.Anything)
+	otherAPIClient.AssertNotCalled(t, "FindCharacterByName", mock.Anything)
+	otherMetrics.AssertExpectations(t)
+
+	// Lookups fall back to the database while Redis is down
+	server.Close()
+	otherRepo.On("FindCharacterByID", "1").Return(&domain.Character{ID: "1", Name: "Goku"}, nil).Once()
+	otherMetrics.On("RecordLookup", "get", domain.LookupLocalHit).Once()
+
+	character, err = secondReplica.GetCharacter(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.CacheSourceLocal, character.Cache.Source)
+	otherRepo.AssertExpectations(t)
+}
+
+func TestCharacterService_CreateCharacter_RemembersUnknownNames(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockUnknownNames := new(MockUnknownNameRepository)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{NotFoundTTL: 10 * time.Minute}
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, mockUnknownNames, mockMetrics, freshness, logger)
+
+	// The first lookup of a typo asks the upstream and records the answer
+	mockRepo.On("FindCharacterByName", "Gokku").Return(nil, nil)
+	mockUnknownNames.On("IsUnknownName", "gokku").Return(false, nil).Once()
+	mockAPIClient.On("FindCharacterByName", "Gokku").Return(nil, nil).Once()
+	mockUnknownNames.On("SaveUnknownName", "gokku", 10*time.Minute).Return(nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupNotFound).Once()
+
+	_, _, err := charService.CreateCharacter(context.Background(), "Gokku")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+
+	// The next one is answered from the record
+	mockUnknownNames.On("IsUnknownName", "gokku").Return(true, nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupKnownUnknown).Once()
+
+	_, _, err = charService.CreateCharacter(context.Background(), "Gokku")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	mockAPIClient.AssertNumberOfCalls(t, "FindCharacterByName", 1)
+
+	// A failing record falls back to the upstream
+	mockUnknownNames.On("IsUnknownName", "gokku").Return(false, errors.New("connection reset")).Once()
+	mockAPIClient.On("FindCharacterByName", "Gokku").Return(nil, nil).Once()
+	mockUnknownNames.On("SaveUnknownName", "gokku", 10*time.Minute).Return(errors.New("connection reset")).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupNotFound).Once()
+
+	_, _, err = charService.CreateCharacter(context.Background(), "Gokku")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	mockAPIClient.AssertExpectations(t)
+	mockUnknownNames.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+}
+
+func TestCharacterService_PurgeUnknownNames(t *testing.T) {
+	mockUnknownNames := new(MockUnknownNameRepository)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	charService := services.NewCharacterService(new(MockCharacterRepository), new(MockDragonBallAPIClient), nil, mockUnknownNames, new(MockCharacterMetrics), domain.FreshnessPolicy{}, logger)
+
+	mockUnknownNames.On("PurgeUnknownNames").Return(int64(2), nil).Once()
+	purged, err := charService.PurgeUnknownNames(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	mockUnknownNames.On("PurgeUnknownNames").Return(int64(0), errors.New("connection reset")).Once()
+	_, err = charService.PurgeUnknownNames(context.Background())
+	assert.Error(t, err)
+	mockUnknownNames.AssertExpectations(t)
+}
+
+func TestCharacterService_ListCharacters_AppliesDefaults(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	expectedPage := &domain.CharacterPage{
+		Items:      []*domain.Character{{ID: "1", Name: "Goku"}},
+		NextCursor: "next",
+	}
+	expectedParams := domain.CharacterListParams{
+		Race:   "Saiyan",
+		SortBy: domain.CharacterSortByName,
+		Limit:  domain.DefaultCharacterPageSize,
+	}
+	mockRepo.On("ListCharacters", expectedParams).Return(expectedPage, nil).Once()
+
+	page, err := charService.ListCharacters(context.Background(), domain.CharacterListParams{Race: "Saiyan"})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPage, page)
+	mockRepo.AssertExpectations(t)
+	mockAPIClient.AssertNotCalled(t, "FindCharacterByName")
+}
+
+func TestCharacterService_ListCharacters_InvalidLimit(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	page, err := charService.ListCharacters(context.Background(), domain.CharacterListParams{Limit: domain.MaxCharacterPageSize + 1})
+	assert.ErrorIs(t, err, domain.ErrValidation)
+	assert.Nil(t, page)
+	mockRepo.AssertNotCalled(t, "ListCharacters")
+}
+
+func TestCharacterService_CreateCharacter_CoalescesConcurrentLookups(t *testing.T) {
+	mockRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	mockMetrics := new(MockCharacterMetrics)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	charService := services.NewCharacterService(mockRepo, mockAPIClient, nil, nil, mockMetrics, domain.FreshnessPolicy{}, logger)
+
+	const callers = 50
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	mockRepo.On("FindCharacterByName", mock.Anything).Return(nil, nil).Once()
+	mockAPIClient.On("FindCharacterByName", mock.Anything).
+		Run(func(mock.Arguments) {
+			close(fetching)
+			<-release
+		}).
+		Return(&domain.Character{ID: "1", Name: "Goku", Race: "Saiyan"}, nil).Once()
+	mockRepo.On("SaveCharacter", mock.AnythingOfType("*domain.Character")).Return(nil).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupUpstreamFetch).Once()
+	mockMetrics.On("RecordLookup", "create", domain.LookupCoalesced).Times(callers - 1)
+
+	var wg sync.WaitGroup
+	var createdCount atomic.Int32
+	characters := make([]*domain.Character, callers)
+	for i := 0; i < callers; i++ {
+		// Names differing in case and spacing are the same lookup
+		name := "Goku"
+		if i%2 == 1 {
+			name = " goku "
+		}
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			character, created, err := charService.CreateCharacter(context.Background(), name)
+			assert.NoError(t, err)
+			if created {
+				createdCount.Add(1)
+			}
+			characters[i] = character
+		}(i, name)
+	}
+
+	<-fetching
+	time.Sleep(50 * time.Millisecond) // Let every caller join the lookup in flight
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), createdCount.Load(), "only the caller that ran the lookup reports a creation")
+	for _, character := range characters {
+		assert.Equal(t, "Goku", character.Name)
+	}
+	assert.NotSame(t, characters[0], characters[1], "every caller gets its own copy")
+	mockRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+	mockMetrics.AssertExpectations(t)
+}
@@ -0,0 +1,528 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/secondary/lrucache"
+	"backend.go.characters.api/internal/adapters/secondary/rediscache"
+	"backend.go.characters.api/internal/core/domain"
+	"backend.go.characters.api/internal/core/services"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock for PlanetRepository
+type MockPlanetRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlanetRepository) SavePlanet(ctx context.Context, planet *domain.Planet) error {
+	args := m.Called(planet)
+	return args.Error(0)
+}
+
+func (m *MockPlanetRepository) SavePlanetList(ctx context.Context, planets []*domain.Planet) error {
+	args := m.Called(planets)
+	return args.Error(0)
+}
+
+func (m *MockPlanetRepository) FindPlanetByID(ctx context.Context, id string) (*domain.Planet, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Planet), args.Error(1)
+}
+
+func (m *MockPlanetRepository) ListPlanets(ctx context.Context) (*domain.PlanetList, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.PlanetList), args.Error(1)
+}
+
+func (m *MockPlanetRepository) LinkPlanetCharacters(ctx context.Context, id string, characterIDs []string) ([]string, error) {
+	args := m.Called(id, characterIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockPlanetRepository) MarkPlanetCharactersImported(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func TestPlanetService_ListPlanets_FromDB(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	expectedPlanets := []*domain.Planet{{ID: "1", Name: "Namek"}, {ID: "3", Name: "Tierra"}}
+	mockPlanetRepo.On("ListPlanets").Return(&domain.PlanetList{Planets: expectedPlanets, ImportedAt: time.Now()}, nil).Once()
+
+	planets, err := planetService.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPlanets, planets)
+	mockPlanetRepo.AssertExpectations(t)
+	mockAPIClient.AssertNotCalled(t, "ListPlanets")
+}
+
+func TestPlanetService_ListPlanets_ImportsFromAPI(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	apiPlanets := []*domain.Planet{{ID: "1", Name: "Namek"}, {ID: "3", Name: "Tierra"}}
+	mockPlanetRepo.On("ListPlanets").Return(&domain.PlanetList{Planets: []*domain.Planet{}}, nil).Once()
+	mockAPIClient.On("ListPlanets").Return(apiPlanets, nil).Once()
+	mockPlanetRepo.On("SavePlanetList", apiPlanets).Return(nil).Once()
+
+	planets, err := planetService.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, apiPlanets, planets)
+	mockPlanetRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+}
+
+func TestPlanetService_ListPlanets_ImportedEmptyList(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, freshness, logger)
+
+	// The upstream listed no planets, which is served until it is stale
+	stored := &domain.PlanetList{Planets: []*domain.Planet{}, ImportedAt: time.Now().Add(-time.Minute)}
+	mockPlanetRepo.On("ListPlanets").Return(stored, nil).Once()
+
+	planets, err := planetService.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, planets)
+	mockAPIClient.AssertNotCalled(t, "ListPlanets")
+}
+
+func TestPlanetService_ListPlanets_ImportsExpiredListAgain(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, freshness, logger)
+
+	stored := &domain.PlanetList{Planets: []*domain.Planet{{ID: "1", Name: "Namek"}}, ImportedAt: time.Now().Add(-48 * time.Hour)}
+	apiPlanets := []*domain.Planet{{ID: "1", Name: "Namek"}, {ID: "2", Name: "Vegeta"}}
+	mockPlanetRepo.On("ListPlanets").Return(stored, nil).Once()
+	mockAPIClient.On("ListPlanets").Return(apiPlanets, nil).Once()
+	mockPlanetRepo.On("SavePlanetList", apiPlanets).Return(nil).Once()
+
+	// Planets added upstream since the last import show up
+	planets, err := planetService.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, planets, 2)
+	mockPlanetRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+}
+
+func TestPlanetService_ListPlanets_ImportsStaleListInBackground(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, freshness, logger)
+
+	stored := &domain.PlanetList{Planets: []*domain.Planet{{ID: "1", Name: "Namek"}}, ImportedAt: time.Now().Add(-2 * time.Hour)}
+	apiPlanets := []*domain.Planet{{ID: "1", Name: "Namek"}, {ID: "2", Name: "Vegeta"}}
+	imported := make(chan struct{})
+	mockPlanetRepo.On("ListPlanets").Return(stored, nil).Once()
+	mockAPIClient.On("ListPlanets").Return(apiPlanets, nil).Once()
+	mockPlanetRepo.On("SavePlanetList", apiPlanets).Run(func(mock.Arguments) { close(imported) }).Return(nil).Once()
+
+	// The stored list is served right away
+	planets, err := planetService.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, stored.Planets, planets)
+
+	select {
+	case <-imported:
+	case <-time.After(time.Second):
+		t.Fatal("stale planet list was not imported again in the background")
+	}
+	mockAPIClient.AssertExpectations(t)
+}
+
+func TestPlanetService_ListPlanets_APIError(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	mockPlanetRepo.On("ListPlanets").Return(&domain.PlanetList{Planets: []*domain.Planet{}}, nil).Once()
+	mockAPIClient.On("ListPlanets").Return(nil, errors.New("connection refused")).Once()
+
+	planets, err := planetService.ListPlanets(context.Background())
+	assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+	assert.Nil(t, planets)
+	mockPlanetRepo.AssertNotCalled(t, "SavePlanetList", mock.Anything)
+}
+
+func TestPlanetService_GetPlanet_FromDB(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	storedPlanet := &domain.Planet{ID: "1", Name: "Namek", UpdatedAt: time.Now().Add(-time.Minute)}
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(storedPlanet, nil).Once()
+
+	planet, err := planetService.GetPlanet(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Namek", planet.Name)
+	assert.Equal(t, domain.CacheSourceLocal, planet.Cache.Source)
+	assert.GreaterOrEqual(t, planet.Cache.AgeSeconds, int64(60))
+	mockAPIClient.AssertNotCalled(t, "FindPlanetByID", mock.Anything)
+}
+
+func TestPlanetService_GetPlanet_FromAPI(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	apiPlanet := &domain.Planet{ID: "1", Name: "Namek", IsDestroyed: true}
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(nil, nil).Once()
+	mockAPIClient.On("FindPlanetByID", "1").Return(apiPlanet, nil).Once()
+	mockPlanetRepo.On("SavePlanet", apiPlanet).Return(nil).Once()
+
+	planet, err := planetService.GetPlanet(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.True(t, planet.IsDestroyed)
+	assert.Equal(t, domain.CacheSourceUpstream, planet.Cache.Source)
+	mockPlanetRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+}
+
+func TestPlanetService_GetPlanet_NotFound(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	mockPlanetRepo.On("FindPlanetByID", "999").Return(nil, nil).Once()
+	mockAPIClient.On("FindPlanetByID", "999").Return(nil, nil).Once()
+
+	planet, err := planetService.GetPlanet(context.Background(), "999")
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, planet)
+	mockPlanetRepo.AssertNotCalled(t, "SavePlanet", mock.Anything)
+}
+
+func TestPlanetService_GetPlanet_ServesStaleAndRefreshesInBackground(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, freshness, logger)
+
+	stalePlanet := &domain.Planet{ID: "1", Name: "Namek", UpdatedAt: time.Now().Add(-2 * time.Hour)}
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(stalePlanet, nil).Once()
+
+	// The refresh runs after the stale planet was served
+	release := make(chan struct{})
+	refreshed := make(chan struct{})
+	mockAPIClient.On("FindPlanetByID", "1").
+		Run(func(mock.Arguments) { <-release }).
+		Return(&domain.Planet{ID: "1", Name: "Namek", IsDestroyed: true}, nil).Once()
+	mockPlanetRepo.On("SavePlanet", mock.MatchedBy(func(p *domain.Planet) bool { return p.IsDestroyed })).
+		Run(func(mock.Arguments) { close(refreshed) }).
+		Return(nil).Once()
+
+	planet, err := planetService.GetPlanet(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.False(t, planet.IsDestroyed)
+	if assert.NotNil(t, planet.Cache) {
+		assert.Equal(t, domain.CacheSourceLocal, planet.Cache.Source)
+		assert.True(t, planet.Cache.Stale)
+	}
+
+	close(release)
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("stale planet was not refreshed in the background")
+	}
+	mockPlanetRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+}
+
+func TestPlanetService_GetPlanet_RefreshesExpired(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{TTL: time.Hour, MaxAge: 24 * time.Hour}
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, freshness, logger)
+
+	importedAt := time.Now().Add(-time.Hour)
+	expiredPlanet := &domain.Planet{ID: "1", Name: "Namek", UpdatedAt: time.Now().Add(-48 * time.Hour), CharactersImportedAt: &importedAt}
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(expiredPlanet, nil).Once()
+	mockAPIClient.On("FindPlanetByID", "1").Return(&domain.Planet{ID: "1", Name: "Namek", IsDestroyed: true}, nil).Once()
+	mockPlanetRepo.On("SavePlanet", mock.Anything).Return(nil).Once()
+
+	planet, err := planetService.GetPlanet(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.True(t, planet.IsDestroyed)
+	// The import of its characters is kept
+	assert.Equal(t, &importedAt, planet.CharactersImportedAt)
+	if assert.NotNil(t, planet.Cache) {
+		assert.Equal(t, domain.CacheSourceUpstream, planet.Cache.Source)
+	}
+	mockPlanetRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+}
+
+func TestPlanetService_ListPlanetCharacters(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockCharRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, mockCharRepo, nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	expectedPage := &domain.CharacterPage{Items: []*domain.Character{{ID: "1", Name: "Goku"}}}
+	expectedParams := domain.CharacterListParams{
+		Race:           "Saiyan",
+		OriginPlanetID: "3",
+		SortBy:         domain.CharacterSortByName,
+		Limit:          domain.DefaultCharacterPageSize,
+	}
+	importedAt := time.Now().Add(-time.Hour)
+	mockPlanetRepo.On("FindPlanetByID", "3").Return(&domain.Planet{ID: "3", Name: "Tierra", CharactersImportedAt: &importedAt}, nil).Once()
+	mockCharRepo.On("ListCharacters", expectedParams).Return(expectedPage, nil).Once()
+
+	// Characters already imported are listed from the local database only
+	page, err := planetService.ListPlanetCharacters(context.Background(), "3", domain.CharacterListParams{Race: "Saiyan"})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPage, page)
+	mockCharRepo.AssertExpectations(t)
+	mockAPIClient.AssertNotCalled(t, "ListPlanetCharacterIDs", mock.Anything)
+}
+
+func TestPlanetService_ListPlanetCharacters_ImportsOnFirstListing(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockCharRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, mockCharRepo, nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(&domain.Planet{ID: "1", Name: "Namek"}, nil).Once()
+	mockAPIClient.On("ListPlanetCharacterIDs", "1").Return([]string{"3", "27", "28", "999"}, nil).Once()
+	// Stored characters are linked in one batch, missing ones are fetched for their full profile
+	mockPlanetRepo.On("LinkPlanetCharacters", "1", []string{"3", "27", "28", "999"}).Return([]string{"3"}, nil).Once()
+	dende := &domain.Character{ID: "27", Name: "Dende", OriginPlanet: &domain.Planet{ID: "1", Name: "Namek"}}
+	cargo := &domain.Character{ID: "28", Name: "Cargo"}
+	mockAPIClient.On("FindCharacterByID", "27").Return(dende, nil).Once()
+	mockAPIClient.On("FindCharacterByID", "28").Return(cargo, nil).Once()
+	mockAPIClient.On("FindCharacterByID", "999").Return(nil, nil).Once()
+	mockCharRepo.On("SaveCharacter", mock.MatchedBy(func(character *domain.Character) bool { return character.ID == "27" })).Return(nil).Once()
+	// A profile without an origin planet gets the listed one
+	mockCharRepo.On("SaveCharacter", mock.MatchedBy(func(character *domain.Character) bool {
+		return character.ID == "28" && character.OriginPlanet != nil && character.OriginPlanet.ID == "1"
+	})).Return(nil).Once()
+	mockPlanetRepo.On("MarkPlanetCharactersImported", "1").Return(nil).Once()
+
+	expectedPage := &domain.CharacterPage{Items: []*domain.Character{{ID: "27", Name: "Dende"}, {ID: "3", Name: "Piccolo"}}}
+	mockCharRepo.On("ListCharacters", mock.MatchedBy(func(params domain.CharacterListParams) bool {
+		return params.OriginPlanetID == "1"
+	})).Return(expectedPage, nil).Once()
+
+	page, err := planetService.ListPlanetCharacters(context.Background(), "1", domain.CharacterListParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPage, page)
+	mockCharRepo.AssertExpectations(t)
+	mockAPIClient.AssertExpectations(t)
+	mockPlanetRepo.AssertExpectations(t)
+}
+
+func TestPlanetService_ListPlanetCharacters_InvalidatesLinkedCharacters(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockCharRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	cache := rediscache.NewCharacterCache(client, logger, rediscache.Options{})
+	memory := lrucache.NewCharacterRepository(mockCharRepo, logger, lrucache.Options{})
+
+	planetService := services.NewPlanetService(mockPlanetRepo, memory, cache, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	// Piccolo is cached without the origin planet he is about to be linked to
+	piccolo := &domain.Character{ID: "3", Name: "Piccolo"}
+	mockCharRepo.On("FindCharacterByID", "3").Return(piccolo, nil).Once()
+	_, err := memory.FindCharacterByID(context.Background(), "3")
+	assert.NoError(t, err)
+	assert.NoError(t, cache.SetCharacter(context.Background(), piccolo))
+
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(&domain.Planet{ID: "1", Name: "Namek"}, nil).Once()
+	mockAPIClient.On("ListPlanetCharacterIDs", "1").Return([]string{"3"}, nil).Once()
+	mockPlanetRepo.On("LinkPlanetCharacters", "1", []string{"3"}).Return([]string{"3"}, nil).Once()
+	mockPlanetRepo.On("MarkPlanetCharactersImported", "1").Return(nil).Once()
+	mockCharRepo.On("ListCharacters", mock.Anything).Return(&domain.CharacterPage{Items: []*domain.Character{}}, nil).Once()
+
+	_, err = planetService.ListPlanetCharacters(context.Background(), "1", domain.CharacterListParams{})
+	assert.NoError(t, err)
+
+	// Both caches dropped their copy, the next lookup reads the linked row
+	cached, err := cache.GetCharacterByID(context.Background(), "3")
+	assert.NoError(t, err)
+	assert.Nil(t, cached)
+	linked := &domain.Character{ID: "3", Name: "Piccolo", OriginPlanet: &domain.Planet{ID: "1", Name: "Namek"}}
+	mockCharRepo.On("FindCharacterByID", "3").Return(linked, nil).Once()
+	character, err := memory.FindCharacterByID(context.Background(), "3")
+	assert.NoError(t, err)
+	if assert.NotNil(t, character.OriginPlanet) {
+		assert.Equal(t, "1", character.OriginPlanet.ID)
+	}
+	mockCharRepo.AssertExpectations(t)
+	mockPlanetRepo.AssertExpectations(t)
+}
+
+func TestPlanetService_ListPlanetCharacters_CharacterImportFailure(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockCharRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, mockCharRepo, nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(&domain.Planet{ID: "1", Name: "Namek"}, nil).Once()
+	mockAPIClient.On("ListPlanetCharacterIDs", "1").Return([]string{"27", "28"}, nil).Once()
+	mockPlanetRepo.On("LinkPlanetCharacters", "1", []string{"27", "28"}).Return([]string{}, nil).Once()
+	dende := &domain.Character{ID: "27", Name: "Dende", OriginPlanet: &domain.Planet{ID: "1", Name: "Namek"}}
+	mockAPIClient.On("FindCharacterByID", "27").Return(dende, nil).Once()
+	mockAPIClient.On("FindCharacterByID", "28").Return(nil, errors.New("connection reset")).Once()
+	mockCharRepo.On("SaveCharacter", mock.Anything).Return(nil).Once()
+
+	expectedPage := &domain.CharacterPage{Items: []*domain.Character{{ID: "27", Name: "Dende"}}}
+	mockCharRepo.On("ListCharacters", mock.Anything).Return(expectedPage, nil).Once()
+
+	// The page is served with what was imported, the import is retried on the next listing
+	page, err := planetService.ListPlanetCharacters(context.Background(), "1", domain.CharacterListParams{})
+	assert.NoError(t, err)
+	assert.Equal(t, expectedPage, page)
+	mockAPIClient.AssertExpectations(t)
+	mockCharRepo.AssertExpectations(t)
+	mockPlanetRepo.AssertNotCalled(t, "MarkPlanetCharactersImported", mock.Anything)
+}
+
+func TestPlanetService_ListPlanetCharacters_ImportError(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockCharRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	freshness := domain.FreshnessPolicy{MaxAge: 24 * time.Hour}
+	planetService := services.NewPlanetService(mockPlanetRepo, mockCharRepo, nil, mockAPIClient, freshness, logger)
+
+	// Imported past the max age, so imported again
+	importedAt := time.Now().Add(-48 * time.Hour)
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(&domain.Planet{ID: "1", Name: "Namek", UpdatedAt: time.Now(), CharactersImportedAt: &importedAt}, nil).Once()
+	mockAPIClient.On("ListPlanetCharacterIDs", "1").Return(nil, errors.New("connection refused")).Once()
+
+	page, err := planetService.ListPlanetCharacters(context.Background(), "1", domain.CharacterListParams{})
+	assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+	assert.Nil(t, page)
+	mockCharRepo.AssertNotCalled(t, "ListCharacters", mock.Anything)
+	mockPlanetRepo.AssertNotCalled(t, "MarkPlanetCharactersImported", mock.Anything)
+}
+
+func TestPlanetService_ListPlanetCharacters_UnknownPlanet(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockCharRepo := new(MockCharacterRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, mockCharRepo, nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	mockPlanetRepo.On("FindPlanetByID", "999").Return(nil, nil).Once()
+	mockAPIClient.On("FindPlanetByID", "999").Return(nil, nil).Once()
+
+	page, err := planetService.ListPlanetCharacters(context.Background(), "999", domain.CharacterListParams{})
+	assert.ErrorIs(t, err, domain.ErrNotFound)
+	assert.Nil(t, page)
+	mockCharRepo.AssertNotCalled(t, "ListCharacters", mock.Anything)
+}
+
+func TestPlanetService_GetPlanet_CoalescesConcurrentFetches(t *testing.T) {
+	mockPlanetRepo := new(MockPlanetRepository)
+	mockAPIClient := new(MockDragonBallAPIClient)
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	planetService := services.NewPlanetService(mockPlanetRepo, new(MockCharacterRepository), nil, mockAPIClient, domain.FreshnessPolicy{}, logger)
+
+	const callers = 10
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	mockPlanetRepo.On("FindPlanetByID", "1").Return(nil, nil)
+	mockAPIClient.On("FindPlanetByID", "1").
+		Run(func(mock.Arguments) {
+			close(fetching)
+			<-release
+		}).
+		Return(&domain.Planet{ID: "1", Name: "Namek"}, nil).Once()
+	mockPlanetRepo.On("SavePlanet", mock.AnythingOfType("*domain.Planet")).Return(nil).Once()
+
+	var wg sync.WaitGroup
+	planets := make([]*domain.Planet, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			planet, err := planetService.GetPlanet(context.Background(), "1")
+			assert.NoError(t, err)
+			planets[i] = planet
+		}(i)
+	}
+
+	<-fetching
+	// A caller giving up does not cancel the fetch the others wait for
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := planetService.GetPlanet(ctx, "1")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	time.Sleep(50 * time.Millisecond) // Let every caller join the fetch in flight
+	close(release)
+	wg.Wait()
+
+	for _, planet := range planets {
+		assert.Equal(t, "Namek", planet.Name)
+	}
+	assert.NotSame(t, planets[0], planets[1], "every caller gets its own copy")
+	mockAPIClient.AssertExpectations(t)
+	mockPlanetRepo.AssertExpectations(t)
+}
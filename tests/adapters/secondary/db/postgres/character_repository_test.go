@@ -1,7 +1,11 @@
 package postgres_test
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/base64"
+	"errors"
 	"log/slog"
 	"os"
 	"testing"
@@ -10,9 +14,30 @@ import (
 	"backend.go.characters.api/internal/adapters/secondary/db/postgres"
 	"backend.go.characters.api/internal/core/domain"
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 )
 
+var characterColumns = []string{"id", "name", "ki", "max_ki", "race", "gender", "description", "image_url",
+	"affiliation", "deleted_at", "transformations", "created_at", "updated_at", "planet_id", "planet_name",
+	"planet_is_destroyed", "planet_description", "planet_image_url", "planet_deleted_at", "planet_created_at", "planet_updated_at"}
+
+// kiSortColumns are the characterColumns followed by the stored ki sort key,
+// as selected when sorting by ki.
+var kiSortColumns = append(append([]string{}, characterColumns...), "ki_magnitude", "ki_significand")
+
+// characterRow is a characters row with an empty profile and no origin planet.
+func characterRow(id, name, ki, race string) []driver.Value {
+	return []driver.Value{id, name, ki, "", race, "", "", "", "", nil, []byte("[]"), time.Now(), time.Now(),
+		nil, nil, nil, nil, nil, nil, nil, nil}
+}
+
+// kiSortRow is a characterRow followed by its stored ki sort key.
+func kiSortRow(id, name, ki, magnitude, significand string) []driver.Value {
+	return append(characterRow(id, name, ki, "Saiyan"), magnitude, significand)
+}
+
 func TestCharacterRepositorySaveCharacter(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -21,22 +46,78 @@ func TestCharacterRepositorySaveCharacter(t *testing.T) {
 	defer db.Close()
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	repo := postgres.NewCharacterRepository(db, logger)
+	repo := postgres.NewCharacterRepository(db, logger, nil)
 
 	character := &domain.Character{
-		ID:   "1",
-		Name: "Goku",
-		Ki:   "10000",
-		Race: "Saiyan",
+		ID:           "1",
+		Name:         "Goku",
+		Ki:           domain.NewKi("10000"),
+		MaxKi:        domain.NewKi("90 Septillion"),
+		Race:         "Saiyan",
+		Gender:       "Male",
+		Affiliation:  "Z Fighter",
+		OriginPlanet: &domain.Planet{ID: "3", Name: "Tierra"},
 	}
 
-	// Expect the INSERT or UPDATE query
-	mock.ExpectExec(`INSERT INTO characters`).
-		WithArgs(character.ID, character.Name, character.Ki, character.Race).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Expect the origin planet then the character to be upserted in one
+	// transaction, both reporting the stored timestamps
+	createdAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO planets .* RETURNING created_at, updated_at`).
+		WithArgs("3", "Tierra", false, "", "", nil, false).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(createdAt, createdAt))
+	mock.ExpectQuery(`INSERT INTO characters .* RETURNING created_at, updated_at, origin_planet_id`).
+		WithArgs(character.ID, character.Name, "10000", "4", "1", "90 Septillion", character.Race, "Male", "", "", "Z Fighter", nil, "3", []byte(`[]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at", "origin_planet_id"}).AddRow(createdAt, updatedAt, "3"))
+	mock.ExpectCommit()
 
-	err = repo.SaveCharacter(character)
+	err = repo.SaveCharacter(context.Background(), character)
 	assert.NoError(t, err)
+	assert.Equal(t, createdAt, character.CreatedAt)
+	assert.Equal(t, updatedAt, character.UpdatedAt)
+	assert.Equal(t, createdAt, character.OriginPlanet.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// A failed character upsert rolls the planet back
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO planets`).WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(createdAt, createdAt))
+	mock.ExpectQuery(`INSERT INTO characters`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	assert.Error(t, repo.SaveCharacter(context.Background(), character))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharacterRepositorySaveCharacterKeepsLinkedOriginPlanet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	// A refreshed profile without an origin planet leaves the linked one in
+	// place, and the saved character reports it
+	character := &domain.Character{ID: "3", Name: "Piccolo", Ki: domain.NewKi("3.000.000"), Race: "Namekian"}
+	savedAt := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO characters .* origin_planet_id = COALESCE\(EXCLUDED.origin_planet_id, characters.origin_planet_id\).* RETURNING created_at, updated_at, origin_planet_id`).
+		WithArgs("3", "Piccolo", "3.000.000", "6", "3", "", "Namekian", "", "", "", "", nil, nil, []byte(`[]`)).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at", "origin_planet_id"}).AddRow(savedAt, savedAt, "1"))
+	mock.ExpectQuery(`SELECT id, name, .* FROM planets WHERE id = \$1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "is_destroyed", "description", "image_url", "deleted_at", "characters_imported_at", "created_at", "updated_at"}).
+			AddRow("1", "Namek", true, "", "", nil, nil, savedAt, savedAt))
+	mock.ExpectCommit()
+
+	assert.NoError(t, repo.SaveCharacter(context.Background(), character))
+	if assert.NotNil(t, character.OriginPlanet) {
+		assert.Equal(t, "1", character.OriginPlanet.ID)
+		assert.Equal(t, "Namek", character.OriginPlanet.Name)
+	}
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -48,30 +129,292 @@ func TestCharacterRepositoryFindCharacterByName(t *testing.T) {
 	defer db.Close()
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	repo := postgres.NewCharacterRepository(db, logger)
+	repo := postgres.NewCharacterRepository(db, logger, nil)
 
 	characterName := "Vegeta"
-	rows := sqlmock.NewRows([]string{"id", "name", "ki", "race", "created_at", "updated_at"}).
-		AddRow("2", "Vegeta", "9000", "Saiyan", time.Now(), time.Now())
+	rows := sqlmock.NewRows(characterColumns).
+		AddRow(characterRow("2", "Vegeta", "9000", "Saiyan")...)
 
 	// Expect the SELECT query
-	mock.ExpectQuery(`SELECT id, name, ki, race, created_at, updated_at FROM characters WHERE name ILIKE \$1`).
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE LOWER\(c.name\) = LOWER\(\$1\)`).
 		WithArgs(characterName).
 		WillReturnRows(rows)
 
-	foundCharacter, err := repo.FindCharacterByName(characterName)
+	foundCharacter, err := repo.FindCharacterByName(context.Background(), characterName)
 	assert.NoError(t, err)
 	assert.NotNil(t, foundCharacter)
 	assert.Equal(t, characterName, foundCharacter.Name)
 	assert.NoError(t, mock.ExpectationsWereMet())
 
 	// Test not found case
-	mock.ExpectQuery(`SELECT id, name, ki, race, created_at, updated_at FROM characters WHERE name ILIKE \$1`).
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE LOWER\(c.name\) = LOWER\(\$1\)`).
 		WithArgs("NonExistent").
 		WillReturnError(sql.ErrNoRows)
 
-	notFoundCharacter, err := repo.FindCharacterByName("NonExistent")
+	notFoundCharacter, err := repo.FindCharacterByName(context.Background(), "NonExistent")
 	assert.NoError(t, err)
 	assert.Nil(t, notFoundCharacter)
 	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// A wildcard in the name is compared as is rather than matching any character
+	mock.ExpectQuery(`WHERE LOWER\(c.name\) = LOWER\(\$1\)`).
+		WithArgs("G%").
+		WillReturnError(sql.ErrNoRows)
+
+	wildcardCharacter, err := repo.FindCharacterByName(context.Background(), "G%")
+	assert.NoError(t, err)
+	assert.Nil(t, wildcardCharacter)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharacterRepositoryFindCharacterByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := sqlmock.NewRows(characterColumns).
+		AddRow("1", "Goku", "60.000.000", "90 Septillion", "Saiyan", "Male", "El protagonista de la serie.",
+			"https://dragonball-api.com/characters/goku_normal.webp", "Z Fighter", deletedAt,
+			[]byte(`[{"id":"1","name":"Goku SSJ","ki":"3 Billion"}]`), time.Now(), time.Now(),
+			"3", "Tierra", false, "", "", nil, deletedAt, deletedAt)
+
+	// Expect the SELECT query
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE c.id = \$1`).
+		WithArgs("1").
+		WillReturnRows(rows)
+
+	foundCharacter, err := repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.NotNil(t, foundCharacter)
+	assert.Equal(t, "Goku", foundCharacter.Name)
+	assert.Equal(t, "90 Septillion", foundCharacter.MaxKi.String())
+	assert.Equal(t, "Z Fighter", foundCharacter.Affiliation)
+	assert.Equal(t, deletedAt, *foundCharacter.DeletedAt)
+	assert.Equal(t, &domain.Planet{ID: "3", Name: "Tierra", CreatedAt: deletedAt, UpdatedAt: deletedAt}, foundCharacter.OriginPlanet)
+	assert.Equal(t, "3 Billion", foundCharacter.Transformations[0].Ki.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Test not found case
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE c.id = \$1`).
+		WithArgs("999").
+		WillReturnError(sql.ErrNoRows)
+
+	notFoundCharacter, err := repo.FindCharacterByID(context.Background(), "999")
+	assert.NoError(t, err)
+	assert.Nil(t, notFoundCharacter)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharacterRepositoryListCharacters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	params := domain.CharacterListParams{
+		Race:       "Saiyan",
+		NamePrefix: "Go",
+		SortBy:     domain.CharacterSortByName,
+		Limit:      2,
+	}
+
+	// First page: one extra row means another page follows
+	rows := sqlmock.NewRows(characterColumns).
+		AddRow(characterRow("3", "Gohan", "45.000.000", "Saiyan")...).
+		AddRow(characterRow("1", "Goku", "60.000.000", "Saiyan")...).
+		AddRow(characterRow("10", "Goten", "2.000.000", "Saiyan")...)
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE LOWER\(c.race\) = LOWER\(\$1\) AND c.name ILIKE \$2 ORDER BY c.name ASC, c.id ASC LIMIT \$3`).
+		WithArgs("Saiyan", "Go%", 3).
+		WillReturnRows(rows)
+
+	page, err := repo.ListCharacters(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, "Goku", page.Items[1].Name)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Second page: the cursor resumes after the last row of the first page
+	params.Cursor = page.NextCursor
+	rows = sqlmock.NewRows(characterColumns).
+		AddRow(characterRow("10", "Goten", "2.000.000", "Saiyan")...)
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE LOWER\(c.race\) = LOWER\(\$1\) AND c.name ILIKE \$2 AND \(c.name, c.id\) > \(\$3, \$4\) ORDER BY c.name ASC, c.id ASC LIMIT \$5`).
+		WithArgs("Saiyan", "Go%", "Goku", "1", 3).
+		WillReturnRows(rows)
+
+	page, err = repo.ListCharacters(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Malformed cursors are rejected before querying
+	params.Cursor = "not-a-cursor"
+	_, err = repo.ListCharacters(context.Background(), params)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid cursor")
+}
+
+func TestCharacterRepositoryListCharactersByKi(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	minKi, maxKi := domain.NewKi("1.000.000"), domain.NewKi("2.5 Googolplex")
+	params := domain.CharacterListParams{
+		MinKi:      &minKi,
+		MaxKi:      &maxKi,
+		SortBy:     domain.CharacterSortByKi,
+		Descending: true,
+		Limit:      1,
+	}
+
+	// Bounds compare the (magnitude, significand) sort key of the power level
+	rows := sqlmock.NewRows(kiSortColumns).
+		AddRow(kiSortRow("1", "Goku", "90 Septillion", "25", "9")...).
+		AddRow(kiSortRow("2", "Vegeta", "19.84 Septillion", "25", "1.984")...)
+	mock.ExpectQuery(`SELECT c.id, .*, c.ki_magnitude, c.ki_significand FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE \(c.ki_magnitude, c.ki_significand\) >= \(\$1, \$2\) AND \(c.ki_magnitude, c.ki_significand\) <= \(\$3, \$4\) AND c.ki_magnitude <> -2 ORDER BY c.ki_magnitude DESC, c.ki_significand DESC, c.id DESC LIMIT \$5`).
+		WithArgs("6", "1", "10000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000", "2.5", 2).
+		WillReturnRows(rows)
+
+	page, err := repo.ListCharacters(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "90 Septillion", page.Items[0].Ki.String())
+	assert.Equal(t, 0, page.Items[0].Ki.Cmp(domain.NewKi("90.000.000.000.000.000.000.000.000")))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// The cursor holds the stored sort key and resumes right after it
+	params.MinKi, params.MaxKi = nil, nil
+	params.Cursor = page.NextCursor
+	rows = sqlmock.NewRows(kiSortColumns).
+		AddRow(kiSortRow("2", "Vegeta", "19.84 Septillion", "25", "1.984")...)
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE \(c.ki_magnitude, c.ki_significand, c.id\) < \(\$1, \$2, \$3\) ORDER BY c.ki_magnitude DESC, c.ki_significand DESC, c.id DESC LIMIT \$4`).
+		WithArgs("25", "9", "1", 2).
+		WillReturnRows(rows)
+
+	page, err = repo.ListCharacters(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// A cursor that is not a stored sort key is rejected before querying
+	params.Cursor = base64.RawURLEncoding.EncodeToString([]byte(`{"v":"90 Septillion","id":"1"}`))
+	_, err = repo.ListCharacters(context.Background(), params)
+	assert.ErrorIs(t, err, domain.ErrValidation)
+}
+
+func TestCharacterRepositoryListCharactersByKiResumesOnStoredKey(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+	params := domain.CharacterListParams{SortBy: domain.CharacterSortByKi, Limit: 1}
+
+	// Goku still holds the unknown key although his ki text parses to (7, 6)
+	rows := sqlmock.NewRows(kiSortColumns).
+		AddRow(kiSortRow("1", "Goku", "60.000.000", "-2", "0")...).
+		AddRow(kiSortRow("2", "Krillin", "unknown", "-2", "0")...)
+	mock.ExpectQuery(`SELECT c.id, .*, c.ki_magnitude, c.ki_significand FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id ORDER BY c.ki_magnitude ASC, c.ki_significand ASC, c.id ASC LIMIT \$1`).
+		WithArgs(2).
+		WillReturnRows(rows)
+
+	page, err := repo.ListCharacters(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", page.Items[0].Name)
+
+	// The next page resumes after Goku's stored position, not his parsed one
+	params.Cursor = page.NextCursor
+	rows = sqlmock.NewRows(kiSortColumns).
+		AddRow(kiSortRow("2", "Krillin", "unknown", "-2", "0")...)
+	mock.ExpectQuery(`SELECT .* WHERE \(c.ki_magnitude, c.ki_significand, c.id\) > \(\$1, \$2, \$3\) ORDER BY c.ki_magnitude ASC, c.ki_significand ASC, c.id ASC LIMIT \$4`).
+		WithArgs("-2", "0", "1", 2).
+		WillReturnRows(rows)
+
+	page, err = repo.ListCharacters(context.Background(), params)
+	assert.NoError(t, err)
+	assert.Equal(t, "Krillin", page.Items[0].Name)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharacterRepositoryBackfillKiSortKeys(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	// Rows that parse get their key, the others keep the unknown one
+	mock.ExpectQuery(`SELECT id, ki FROM characters WHERE ki_magnitude = -2 AND ki <> '' AND id > \$1 ORDER BY id LIMIT \$2`).
+		WithArgs("", 500).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ki"}).
+			AddRow("1", "60.000.000").
+			AddRow("2", "unknown").
+			AddRow("3", "0"))
+	mock.ExpectExec(`UPDATE characters SET ki_magnitude = \$2, ki_significand = \$3 WHERE id = \$1 AND ki = \$4 AND ki_magnitude = -2`).
+		WithArgs("1", "7", "6", "60.000.000").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE characters SET`).
+		WithArgs("3", "-1", "0", "0").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT id, ki FROM characters`).
+		WithArgs("3", 500).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "ki"}))
+
+	updated, err := repo.BackfillKiSortKeys(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectQuery(`SELECT id, ki FROM characters`).WillReturnError(errors.New("connection reset"))
+	_, err = repo.BackfillKiSortKeys(context.Background())
+	assert.Error(t, err)
+}
+
+func TestCharacterRepositoryRecordsQueryMetrics(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	registry := prometheus.NewRegistry()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, registry)
+	// A second repository on the same registry shares the collectors
+	postgres.NewCharacterRepository(db, logger, registry)
+
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE c.id = \$1`).
+		WithArgs("1").
+		WillReturnError(sql.ErrNoRows)
+
+	_, err = repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "characters_api_db_query_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(registry, "go_sql_open_connections"))
 }
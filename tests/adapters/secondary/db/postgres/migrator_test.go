@@ -0,0 +1,206 @@
+package postgres_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/secondary/db/postgres"
+	"backend.go.characters.api/migrations"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testMigrations = fstest.MapFS{
+	"000001_create_characters_table.up.sql":   {Data: []byte("CREATE TABLE characters (id VARCHAR(255) PRIMARY KEY);")},
+	"000001_create_characters_table.down.sql": {Data: []byte("DROP TABLE characters;")},
+	"000002_add_ki_column.up.sql":             {Data: []byte("ALTER TABLE characters ADD COLUMN ki VARCHAR(255);")},
+	"000002_add_ki_column.down.sql":           {Data: []byte("ALTER TABLE characters DROP COLUMN ki;")},
+	"README.md":                               {Data: []byte("not a migration")},
+}
+
+func checksum(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// expectLockedSession registers the statements every migrator operation starts with.
+func expectLockedSession(mock sqlmock.Sqlmock, applied *sqlmock.Rows) {
+	mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version, checksum, applied_at FROM schema_migrations ORDER BY version`).WillReturnRows(applied)
+}
+
+func TestMigratorUpAppliesPendingMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	migrator, err := postgres.NewMigrator(db, testMigrations, logger)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), migrator.LatestVersion())
+
+	// Version 1 is already applied, only version 2 runs
+	applied := sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+		AddRow(1, checksum("CREATE TABLE characters (id VARCHAR(255) PRIMARY KEY);"), time.Now())
+	expectLockedSession(mock, applied)
+	mock.ExpectBegin()
+	mock.ExpectExec(`ALTER TABLE characters ADD COLUMN ki`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).
+		WithArgs(2, "add_ki_column", checksum("ALTER TABLE characters ADD COLUMN ki VARCHAR(255);")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = migrator.Up(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorGotoRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	migrator, err := postgres.NewMigrator(db, testMigrations, logger)
+	assert.NoError(t, err)
+
+	applied := sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+		AddRow(1, checksum("CREATE TABLE characters (id VARCHAR(255) PRIMARY KEY);"), time.Now()).
+		AddRow(2, checksum("ALTER TABLE characters ADD COLUMN ki VARCHAR(255);"), time.Now())
+	expectLockedSession(mock, applied)
+	mock.ExpectBegin()
+	mock.ExpectExec(`ALTER TABLE characters DROP COLUMN ki`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations WHERE version = \$1`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = migrator.Goto(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// Unknown versions are rejected before touching the database
+	err = migrator.Goto(context.Background(), 42)
+	assert.Error(t, err)
+}
+
+func TestMigratorRefusesModifiedMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	migrator, err := postgres.NewMigrator(db, testMigrations, logger)
+	assert.NoError(t, err)
+
+	applied := sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+		AddRow(1, checksum("CREATE TABLE characters (id TEXT PRIMARY KEY);"), time.Now())
+	expectLockedSession(mock, applied)
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = migrator.Up(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorDownRefusesModifiedMigrations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	migrator, err := postgres.NewMigrator(db, testMigrations, logger)
+	assert.NoError(t, err)
+
+	// The applied version 2 differs from the embedded one, its down script is not run
+	applied := sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+		AddRow(1, checksum("CREATE TABLE characters (id VARCHAR(255) PRIMARY KEY);"), time.Now()).
+		AddRow(2, checksum("ALTER TABLE characters ADD COLUMN ki TEXT;"), time.Now())
+	expectLockedSession(mock, applied)
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = migrator.Down(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigratorStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	migrator, err := postgres.NewMigrator(db, testMigrations, logger)
+	assert.NoError(t, err)
+
+	applied := sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+		AddRow(1, checksum("CREATE TABLE characters (id VARCHAR(255) PRIMARY KEY);"), time.Now())
+	expectLockedSession(mock, applied)
+	mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	statuses, err := migrator.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[0].Modified)
+	assert.False(t, statuses[1].Applied)
+	assert.Equal(t, "add_ki_column", statuses[1].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEmbeddedMigrationsAreComplete(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	migrator, err := postgres.NewMigrator(db, migrations.FS, logger)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, migrator.LatestVersion(), int64(1))
+}
+
+func TestMigratorCheck(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	migrator, err := postgres.NewMigrator(db, testMigrations, logger)
+	assert.NoError(t, err)
+
+	// Schema behind the embedded migrations
+	mock.ExpectQuery(`SELECT MAX\(version\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(1))
+	err = migrator.Check(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "behind expected version 2")
+
+	// Schema up to date
+	mock.ExpectQuery(`SELECT MAX\(version\) FROM schema_migrations`).
+		WillReturnRows(sqlmock.NewRows([]string{"max"}).AddRow(2))
+	assert.NoError(t, migrator.Check(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,134 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/secondary/db/postgres"
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+var planetColumns = []string{"id", "name", "is_destroyed", "description", "image_url", "deleted_at", "characters_imported_at", "created_at", "updated_at"}
+
+func TestCharacterRepositorySavePlanetList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	planets := []*domain.Planet{
+		{ID: "1", Name: "Namek", IsDestroyed: true},
+		{ID: "3", Name: "Tierra"},
+	}
+	savedAt := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	// Every planet is upserted as listed, in a single transaction
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO planets .* ON CONFLICT \(id\) DO UPDATE .* RETURNING created_at, updated_at`).
+		WithArgs("1", "Namek", true, "", "", nil, true).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(savedAt, savedAt))
+	mock.ExpectQuery(`INSERT INTO planets`).
+		WithArgs("3", "Tierra", false, "", "", nil, true).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "updated_at"}).AddRow(savedAt, savedAt))
+	// Planets dropped from the upstream list since the last import are unlisted
+	mock.ExpectExec(`UPDATE planets SET listed_at = NULL WHERE listed_at IS NOT NULL AND NOT \(id = ANY\(\$1\)\)`).
+		WithArgs(pq.Array([]string{"1", "3"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO planet_list_imports \(singleton, imported_at\) VALUES \(TRUE, NOW\(\)\) ON CONFLICT \(singleton\) DO UPDATE`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	assert.NoError(t, repo.SavePlanetList(context.Background(), planets))
+	assert.Equal(t, savedAt, planets[1].UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// A failure leaves nothing imported
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO planets`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+	assert.Error(t, repo.SavePlanetList(context.Background(), planets))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCharacterRepositoryFindAndListPlanets(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	deletedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock.ExpectQuery(`SELECT id, name, is_destroyed, description, image_url, deleted_at, characters_imported_at, created_at, updated_at FROM planets WHERE id = \$1`).
+		WithArgs("1").
+		WillReturnRows(sqlmock.NewRows(planetColumns).AddRow("1", "Namek", true, "Planeta natal de los Namekianos.", "", deletedAt, nil, time.Now(), time.Now()))
+	planet, err := repo.FindPlanetByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Namek", planet.Name)
+	assert.True(t, planet.IsDestroyed)
+	assert.Equal(t, deletedAt, *planet.DeletedAt)
+	assert.Nil(t, planet.CharactersImportedAt)
+
+	mock.ExpectQuery(`SELECT .* FROM planets WHERE id = \$1`).WithArgs("999").WillReturnError(sql.ErrNoRows)
+	planet, err = repo.FindPlanetByID(context.Background(), "999")
+	assert.NoError(t, err)
+	assert.Nil(t, planet)
+
+	// Before the first import the list is empty and undated
+	mock.ExpectQuery(`SELECT imported_at FROM planet_list_imports`).WillReturnError(sql.ErrNoRows)
+	list, err := repo.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, list.Planets)
+	assert.True(t, list.ImportedAt.IsZero())
+
+	// Only the planets of the imported list are listed, dated by the import
+	importedAt := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	mock.ExpectQuery(`SELECT imported_at FROM planet_list_imports`).
+		WillReturnRows(sqlmock.NewRows([]string{"imported_at"}).AddRow(importedAt))
+	mock.ExpectQuery(`SELECT .* FROM planets WHERE listed_at IS NOT NULL ORDER BY name, id`).
+		WillReturnRows(sqlmock.NewRows(planetColumns).
+			AddRow("1", "Namek", true, "", "", nil, nil, time.Now(), time.Now()).
+			AddRow("3", "Tierra", false, "", "", nil, importedAt, time.Now(), time.Now()))
+	list, err = repo.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, list.Planets, 2)
+	assert.Nil(t, list.Planets[1].DeletedAt)
+	assert.Equal(t, importedAt, list.ImportedAt)
+
+	assert.Equal(t, importedAt, *list.Planets[1].CharactersImportedAt)
+
+	// Stored characters without an origin planet are linked to it
+	mock.ExpectQuery(`WITH linked AS \( UPDATE characters SET origin_planet_id = \$1 WHERE id = ANY\(\$2\) AND origin_planet_id IS NULL \) SELECT id FROM characters WHERE id = ANY\(\$2\)`).
+		WithArgs("3", pq.Array([]string{"1", "2", "99"})).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("1").AddRow("2"))
+	stored, err := repo.LinkPlanetCharacters(context.Background(), "3", []string{"1", "2", "99"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, stored)
+
+	mock.ExpectExec(`UPDATE planets SET characters_imported_at = NOW\(\) WHERE id = \$1`).
+		WithArgs("3").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkPlanetCharactersImported(context.Background(), "3"))
+
+	// The characters of a planet are listed through the character list
+	mock.ExpectQuery(`SELECT c.id, .* FROM characters c LEFT JOIN planets p ON p.id = c.origin_planet_id WHERE c.origin_planet_id = \$1 ORDER BY c.name ASC, c.id ASC LIMIT \$2`).
+		WithArgs("3", 21).
+		WillReturnRows(sqlmock.NewRows(characterColumns).AddRow(characterRow("1", "Goku", "60.000.000", "Saiyan")...))
+	page, err := repo.ListCharacters(context.Background(), domain.CharacterListParams{OriginPlanetID: "3", SortBy: domain.CharacterSortByName, Limit: 20})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
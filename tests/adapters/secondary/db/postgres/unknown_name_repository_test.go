@@ -0,0 +1,53 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/secondary/db/postgres"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCharacterRepositoryUnknownNames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	repo := postgres.NewCharacterRepository(db, logger, nil)
+
+	// Recorded for the TTL, in milliseconds, deleting the expired records
+	mock.ExpectExec(`WITH expired AS \( DELETE FROM unknown_character_names WHERE expires_at <= NOW\(\) AND name <> \$1 \) INSERT INTO unknown_character_names .* ON CONFLICT \(name\) DO UPDATE`).
+		WithArgs("gokku", int64(600000)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.SaveUnknownName(context.Background(), "gokku", 10*time.Minute))
+
+	// Only unexpired records count
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM unknown_character_names WHERE name = \$1 AND expires_at > NOW\(\)\)`).
+		WithArgs("gokku").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	unknown, err := repo.IsUnknownName(context.Background(), "gokku")
+	assert.NoError(t, err)
+	assert.True(t, unknown)
+
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs("goku").
+		WillReturnError(errors.New("connection reset"))
+	_, err = repo.IsUnknownName(context.Background(), "goku")
+	assert.Error(t, err)
+
+	mock.ExpectExec(`DELETE FROM unknown_character_names`).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	purged, err := repo.PurgeUnknownNames(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), purged)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,23 +1,57 @@
 package dragonballapi_test
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"backend.go.characters.api/internal/adapters/secondary/dragonballapi"
+	"backend.go.characters.api/internal/adapters/tracing"
+	"backend.go.characters.api/internal/core/domain"
+	"backend.go.characters.api/internal/core/ports"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+type testClient interface {
+	ports.DragonBallAPIClient
+	CircuitState() dragonballapi.CircuitState
+}
+
+// newTestClient builds a client against baseURL, letting the test adjust the
+// default options first.
+func newTestClient(t *testing.T, logger *slog.Logger, baseURL string, configure func(*dragonballapi.Options)) testClient {
+	t.Helper()
+	opts := dragonballapi.DefaultOptions()
+	opts.BaseURL = baseURL
+	if configure != nil {
+		configure(&opts)
+	}
+	client, err := dragonballapi.NewDragonBallAPIClient(logger, opts)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
 func TestDragonBallAPIClientFindCharacterByName(t *testing.T) {
-	// Mock HTTP server
+	// Mock HTTP server, the list leaves out the origin planet and transformations
+	var paths []string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		assert.Equal(t, "/api/characters", r.URL.Path)
-		if r.URL.Path == "/api/characters" {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/characters":
 			response := map[string][]map[string]string{
 				"items": {
 					{"id": "1", "name": "Goku", "ki": "9000", "race": "Saiyan"},
@@ -25,35 +59,74 @@ func TestDragonBallAPIClientFindCharacterByName(t *testing.T) {
 				},
 			}
 			json.NewEncoder(w).Encode(response)
-		} else {
+		case "/api/characters/1":
+			w.Write([]byte(`{"id":1,"name":"Goku","ki":"9000","maxKi":"90 Septillion","race":"Saiyan",
+				"originPlanet":{"id":3,"name":"Tierra","isDestroyed":false},
+				"transformations":[{"id":5,"name":"Goku SSJ","ki":"3 Billion"}]}`))
+		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
 	}))
 	defer server.Close()
 
-	// Override base URL for testing
-	//originalBaseURL := dragonballapi.BaseURL // Assuming you made BaseURL public in dragonballapi/client.go for testing
-	originalBaseURL := "https://dragonball-api.com/api"
-	dragonballapi.BaseURL = server.URL + "/api"
-	defer func() { dragonballapi.BaseURL = originalBaseURL }()
-
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	client := dragonballapi.NewDragonBallAPIClient(logger)
+	client := newTestClient(t, logger, server.URL+"/api", nil)
 
-	// Test case: Character found
-	character, err := client.FindCharacterByName("Goku")
+	// Test case: Character found, then fetched for its full profile
+	character, err := client.FindCharacterByName(context.Background(), "Goku")
 	assert.NoError(t, err)
 	assert.NotNil(t, character)
 	assert.Equal(t, "Goku", character.Name)
 	assert.Equal(t, "1", character.ID)
 	assert.Equal(t, "Saiyan", character.Race)
+	assert.Equal(t, "Tierra", character.OriginPlanet.Name)
+	assert.Len(t, character.Transformations, 1)
+	assert.Equal(t, []string{"/api/characters", "/api/characters/1"}, paths)
 
 	// Test case: Character not found
-	character, err = client.FindCharacterByName("Frieza")
+	character, err = client.FindCharacterByName(context.Background(), "Frieza")
 	assert.NoError(t, err) // No error, just character is nil
 	assert.Nil(t, character)
 }
 
+func TestDragonBallAPIClientDecodesFullProfile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":1,"name":"Goku","ki":"60.000.000","maxKi":"90 Septillion","race":"Saiyan",
+			"gender":"Male","description":"El protagonista de la serie.","image":"https://dragonball-api.com/characters/goku_normal.webp",
+			"affiliation":"Z Fighter","deletedAt":null,
+			"originPlanet":{"id":3,"name":"Tierra","isDestroyed":false,"description":"Planeta Tierra.",
+				"image":"https://dragonball-api.com/planetas/Tierra_Dragon_Ball_Z.webp","deletedAt":null},
+			"transformations":[
+				{"id":1,"name":"Goku SSJ","image":"https://dragonball-api.com/transformaciones/goku_ssj.webp","ki":"3 Billion","deletedAt":null},
+				{"id":2,"name":"Goku SSJ2","image":"https://dragonball-api.com/transformaciones/goku_ssj2.webp","ki":"6 Billion","deletedAt":"2024-01-02T03:04:05Z"}]}`))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	character, err := client.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "90 Septillion", character.MaxKi.String())
+	assert.True(t, character.MaxKi.Known())
+	assert.Equal(t, "Male", character.Gender)
+	assert.Equal(t, "El protagonista de la serie.", character.Description)
+	assert.Equal(t, "https://dragonball-api.com/characters/goku_normal.webp", character.ImageURL)
+	assert.Equal(t, "Z Fighter", character.Affiliation)
+	assert.Nil(t, character.DeletedAt)
+	assert.Equal(t, &domain.Planet{
+		ID:          "3",
+		Name:        "Tierra",
+		Description: "Planeta Tierra.",
+		ImageURL:    "https://dragonball-api.com/planetas/Tierra_Dragon_Ball_Z.webp",
+	}, character.OriginPlanet)
+	assert.Len(t, character.Transformations, 2)
+	assert.Equal(t, "Goku SSJ", character.Transformations[0].Name)
+	assert.Equal(t, "3 Billion", character.Transformations[0].Ki.String())
+	assert.Nil(t, character.Transformations[0].DeletedAt)
+	assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(*character.Transformations[1].DeletedAt))
+}
+
 func TestDragonBallAPIClientFindCharacterByID(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/api/characters/1" {
@@ -72,27 +145,280 @@ func TestDragonBallAPIClientFindCharacterByID(t *testing.T) {
 	}))
 	defer server.Close()
 
-	originalBaseURL := dragonballapi.BaseURL
-	dragonballapi.BaseURL = server.URL + "/api"
-	defer func() { dragonballapi.BaseURL = originalBaseURL }()
-
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	client := dragonballapi.NewDragonBallAPIClient(logger)
+	client := newTestClient(t, logger, server.URL+"/api", nil)
 
 	// Test case: Character found by ID
-	character, err := client.FindCharacterByID("1")
+	character, err := client.FindCharacterByID(context.Background(), "1")
 	assert.NoError(t, err)
 	assert.NotNil(t, character)
 	assert.Equal(t, "Gohan", character.Name)
 	assert.Equal(t, "1", character.ID)
 
 	// Test case: Character not found by ID
-	character, err = client.FindCharacterByID("999")
+	character, err = client.FindCharacterByID(context.Background(), "999")
 	assert.NoError(t, err)
 	assert.Nil(t, character)
 
 	// Test case: API error
-	_, err = client.FindCharacterByID("invalid_id") // This will hit the /api/characters/invalid_id route, causing 500
+	_, err = client.FindCharacterByID(context.Background(), "invalid_id") // This will hit the /api/characters/invalid_id route, causing 500
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Dragon Ball API returned status 500")
+	assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestDragonBallAPIClientUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close() // Nothing listens on this address anymore
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	_, err := client.FindCharacterByName(context.Background(), "Goku")
+	assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestDragonBallAPIClientHonoursContextDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release // Never answers before the caller gives up
+	}))
+	defer server.Close()
+	defer close(release)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.FindCharacterByID(ctx, "1")
+	assert.ErrorIs(t, err, domain.ErrUpstreamTimeout)
+}
+
+func TestDragonBallAPIClientFindCharacterByNameFollowsPagination(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"items":[{"id":1,"name":"Goku","ki":"60.000.000","race":"Saiyan"}],
+			"meta":{"totalItems":3,"itemCount":1,"itemsPerPage":1,"totalPages":3,"currentPage":1},
+			"links":{"first":"/api/characters?page=1","previous":"","next":"/api/characters?page=2&limit=1","last":"/api/characters?page=3"}}`,
+		"2": `{"items":[{"id":2,"name":"Vegeta","ki":"54.000.000","race":"Saiyan"}],
+			"meta":{"totalItems":3,"itemCount":1,"itemsPerPage":1,"totalPages":3,"currentPage":2},
+			"links":{"first":"/api/characters?page=1","previous":"/api/characters?page=1","next":"/api/characters?page=3&limit=1","last":"/api/characters?page=3"}}`,
+		"3": `{"items":[{"id":3,"name":"Piccolo","ki":"2.000.000","race":"Namekian"}],
+			"meta":{"totalItems":3,"itemCount":1,"itemsPerPage":1,"totalPages":3,"currentPage":3},
+			"links":{"first":"/api/characters?page=1","previous":"/api/characters?page=2","next":"","last":"/api/characters?page=3"}}`,
+	}
+	var requestedPages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/characters/2" {
+			w.Write([]byte(`{"id":2,"name":"Vegeta","ki":"54.000.000","race":"Saiyan"}`))
+			return
+		}
+		assert.Equal(t, "/api/characters", r.URL.Path)
+		page := r.URL.Query().Get("page")
+		requestedPages = append(requestedPages, page)
+		w.Write([]byte(pages[page]))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	// Test case: Character on a later page, stops as soon as it is found
+	character, err := client.FindCharacterByName(context.Background(), "Vegeta")
+	assert.NoError(t, err)
+	assert.NotNil(t, character)
+	assert.Equal(t, "2", character.ID)
+	assert.Equal(t, []string{"1", "2"}, requestedPages)
+
+	// Test case: Character on no page, walks every page
+	requestedPages = nil
+	character, err = client.FindCharacterByName(context.Background(), "Frieza")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+	assert.Equal(t, []string{"1", "2", "3"}, requestedPages)
+}
+
+func TestDragonBallAPIClientListPlanets(t *testing.T) {
+	pages := map[string]string{
+		"1": `{"items":[{"id":1,"name":"Namek","isDestroyed":true,"description":"Planeta natal de los Namekianos.","image":"https://dragonball-api.com/planetas/Namek.webp"}],
+			"meta":{"totalItems":2,"itemCount":1,"itemsPerPage":1,"totalPages":2,"currentPage":1},
+			"links":{"first":"/api/planets?page=1","previous":"","next":"/api/planets?page=2&limit=1","last":"/api/planets?page=2"}}`,
+		"2": `{"items":[{"id":3,"name":"Tierra","isDestroyed":false,"deletedAt":"2024-01-02T03:04:05Z"}],
+			"meta":{"totalItems":2,"itemCount":1,"itemsPerPage":1,"totalPages":2,"currentPage":2},
+			"links":{"first":"/api/planets?page=1","previous":"/api/planets?page=1","next":"","last":"/api/planets?page=2"}}`,
+	}
+	var requestedPages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/planets", r.URL.Path)
+		page := r.URL.Query().Get("page")
+		requestedPages = append(requestedPages, page)
+		w.Write([]byte(pages[page]))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	planets, err := client.ListPlanets(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2"}, requestedPages)
+	assert.Len(t, planets, 2)
+	assert.Equal(t, "1", planets[0].ID)
+	assert.True(t, planets[0].IsDestroyed)
+	assert.Equal(t, "https://dragonball-api.com/planetas/Namek.webp", planets[0].ImageURL)
+	assert.Equal(t, "Tierra", planets[1].Name)
+	assert.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(*planets[1].DeletedAt))
+}
+
+func TestDragonBallAPIClientFindPlanetByID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/planets/1":
+			w.Write([]byte(`{"id":1,"name":"Namek","isDestroyed":true,"characters":[{"id":27,"name":"Piccolo"}]}`))
+		case "/api/planets/999":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	// Test case: Planet found by ID
+	planet, err := client.FindPlanetByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, &domain.Planet{ID: "1", Name: "Namek", IsDestroyed: true}, planet)
+
+	// Test case: Planet not found by ID
+	planet, err = client.FindPlanetByID(context.Background(), "999")
+	assert.NoError(t, err)
+	assert.Nil(t, planet)
+
+	// Test case: Characters listed for the planet
+	ids, err := client.ListPlanetCharacterIDs(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"27"}, ids)
+
+	ids, err = client.ListPlanetCharacterIDs(context.Background(), "999")
+	assert.NoError(t, err)
+	assert.Nil(t, ids)
+
+	// Test case: API error
+	_, err = client.FindPlanetByID(context.Background(), "invalid_id")
+	assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+}
+
+func TestDragonBallAPIClientOptions(t *testing.T) {
+	t.Parallel()
+
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		assert.Equal(t, "/v2/characters/1", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "name": "Goku", "ki": "60.000.000", "race": "Saiyan"})
+	}))
+	defer server.Close()
+
+	// A custom RoundTripper sees every request
+	var roundTrips atomic.Int32
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		roundTrips.Add(1)
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/v2/", func(opts *dragonballapi.Options) {
+		opts.UserAgent = "characters-test/1.0"
+		opts.Transport = transport
+	})
+
+	character, err := client.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", character.Name)
+	assert.Equal(t, "characters-test/1.0", userAgent)
+	assert.Equal(t, int32(1), roundTrips.Load())
+}
+
+func TestDragonBallAPIClientInvalidCAFile(t *testing.T) {
+	t.Parallel()
+
+	opts := dragonballapi.DefaultOptions()
+	opts.TLS.CAFile = "/nonexistent/ca.pem"
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	_, err := dragonballapi.NewDragonBallAPIClient(logger, opts)
+	assert.Error(t, err)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestDragonBallAPIClientPropagatesTraceContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing.Install(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(noop.NewTracerProvider()) })
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "name": "Goku", "ki": "60.000.000", "race": "Saiyan"})
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.FindCharacterByID(ctx, "1")
+	parent.End()
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 2) {
+		upstream := spans[0]
+		assert.Equal(t, "GET /characters/{id}", upstream.Name)
+		assert.Equal(t, parent.SpanContext().TraceID(), upstream.SpanContext.TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), upstream.Parent.SpanID())
+		// The upstream continues the trace as a child of the attempt span
+		assert.Equal(t, "00-"+upstream.SpanContext.TraceID().String()+"-"+upstream.SpanContext.SpanID().String()+"-01", traceparent)
+	}
+}
+
+func TestDragonBallAPIClientCoalescesConcurrentFetches(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "name": "Goku", "ki": "60.000.000", "race": "Saiyan"})
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", nil)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			character, err := client.FindCharacterByID(context.Background(), "1")
+			assert.NoError(t, err)
+			assert.Equal(t, "Goku", character.Name)
+		}()
+	}
+
+	assert.Eventually(t, func() bool { return hits.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond) // Let every caller join the fetch in flight
+	close(release)
+	wg.Wait()
+	assert.Equal(t, int32(1), hits.Load())
 }
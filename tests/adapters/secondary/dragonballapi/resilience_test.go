@@ -0,0 +1,208 @@
+package dragonballapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/secondary/dragonballapi"
+	"backend.go.characters.api/internal/core/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func fastRetryPolicy() dragonballapi.RetryPolicy {
+	return dragonballapi.RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second}
+}
+
+func TestDragonBallAPIClientRetriesTransientFailures(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "name": "Goku", "ki": "60.000.000", "race": "Saiyan"})
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", func(opts *dragonballapi.Options) {
+		opts.RetryPolicy = fastRetryPolicy()
+	})
+
+	character, err := client.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", character.Name)
+	assert.Equal(t, int32(3), hits.Load())
+	assert.Equal(t, dragonballapi.CircuitClosed, client.CircuitState())
+}
+
+func TestDragonBallAPIClientHonoursRetryAfter(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "name": "Goku", "ki": "60.000.000", "race": "Saiyan"})
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", func(opts *dragonballapi.Options) {
+		opts.RetryPolicy = fastRetryPolicy()
+	})
+
+	start := time.Now()
+	character, err := client.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.NotNil(t, character)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestDragonBallAPIClientFillsRetryDelayDefaults(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "name": "Goku", "ki": "60.000.000", "race": "Saiyan"})
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", func(opts *dragonballapi.Options) {
+		// Only the retries are set, the delays come from DefaultRetryPolicy
+		opts.RetryPolicy = dragonballapi.RetryPolicy{MaxRetries: 1}
+	})
+
+	// A zero MaxDelay would cap the Retry-After wait to nothing
+	start := time.Now()
+	character, err := client.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.NotNil(t, character)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestDragonBallAPIClientDoesNotRetryNotFound(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", func(opts *dragonballapi.Options) {
+		opts.RetryPolicy = fastRetryPolicy()
+	})
+
+	character, err := client.FindCharacterByID(context.Background(), "999")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestDragonBallAPIClientCircuitBreakerFailsFast(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", func(opts *dragonballapi.Options) {
+		opts.RetryPolicy = dragonballapi.RetryPolicy{MaxRetries: 0}
+		opts.CircuitBreaker = dragonballapi.CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: time.Minute}
+	})
+
+	// Two failed calls open the circuit
+	for i := 0; i < 2; i++ {
+		_, err := client.FindCharacterByID(context.Background(), "1")
+		assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+	}
+	assert.Equal(t, dragonballapi.CircuitOpen, client.CircuitState())
+
+	// Further calls fail fast without reaching the upstream
+	_, err := client.FindCharacterByID(context.Background(), "1")
+	assert.ErrorIs(t, err, dragonballapi.ErrCircuitOpen)
+	assert.ErrorIs(t, err, domain.ErrUpstreamUnavailable)
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestCircuitBreakerHalfOpenProbe(t *testing.T) {
+	breaker := dragonballapi.NewCircuitBreaker(dragonballapi.CircuitBreakerSettings{FailureThreshold: 1, OpenTimeout: 20 * time.Millisecond})
+
+	assert.NoError(t, breaker.Allow())
+	breaker.RecordFailure()
+	assert.Equal(t, dragonballapi.CircuitOpen, breaker.State())
+	assert.ErrorIs(t, breaker.Allow(), dragonballapi.ErrCircuitOpen)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, dragonballapi.CircuitHalfOpen, breaker.State())
+
+	// Only one probe is let through while half-open
+	assert.NoError(t, breaker.Allow())
+	assert.ErrorIs(t, breaker.Allow(), dragonballapi.ErrCircuitOpen)
+
+	// A failed probe opens the circuit again, a successful one closes it
+	breaker.RecordFailure()
+	assert.Equal(t, dragonballapi.CircuitOpen, breaker.State())
+	time.Sleep(30 * time.Millisecond)
+	assert.NoError(t, breaker.Allow())
+	breaker.RecordSuccess()
+	assert.Equal(t, dragonballapi.CircuitClosed, breaker.State())
+}
+
+func TestDragonBallAPIClientRecordsUpstreamMetrics(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if r.URL.Path == "/api/characters/999" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id": "1", "name": "Goku", "ki": "60.000.000", "race": "Saiyan"})
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	client := newTestClient(t, logger, server.URL+"/api", func(opts *dragonballapi.Options) {
+		opts.RetryPolicy = fastRetryPolicy()
+		opts.Registerer = registry
+	})
+
+	_, err := client.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	_, err = client.FindCharacterByID(context.Background(), "999")
+	assert.NoError(t, err)
+
+	// The retried 502 is an error, the 404 is a valid answer
+	expected := `
+# HELP characters_api_upstream_errors_total Dragon Ball API errors by endpoint and reason.
+# TYPE characters_api_upstream_errors_total counter
+characters_api_upstream_errors_total{endpoint="/characters/{id}",reason="status"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "characters_api_upstream_errors_total"))
+	assert.Equal(t, 3, testutil.CollectAndCount(registry, "characters_api_upstream_request_duration_seconds"))
+}
@@ -0,0 +1,257 @@
+package lrucache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/secondary/lrucache"
+	"backend.go.characters.api/internal/core/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Mock for the wrapped CharacterRepository
+type MockCharacterRepository struct {
+	mock.Mock
+}
+
+func (m *MockCharacterRepository) SaveCharacter(ctx context.Context, character *domain.Character) error {
+	args := m.Called(character)
+	return args.Error(0)
+}
+
+func (m *MockCharacterRepository) FindCharacterByName(ctx context.Context, name string) (*domain.Character, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Character), args.Error(1)
+}
+
+func (m *MockCharacterRepository) FindCharacterByID(ctx context.Context, id string) (*domain.Character, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Character), args.Error(1)
+}
+
+func (m *MockCharacterRepository) ListCharacters(ctx context.Context, params domain.CharacterListParams) (*domain.CharacterPage, error) {
+	args := m.Called(params)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.CharacterPage), args.Error(1)
+}
+
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, nil))
+}
+
+func TestCharacterRepositoryServesRepeatedLookupsFromMemory(t *testing.T) {
+	next := new(MockCharacterRepository)
+	registry := prometheus.NewRegistry()
+	repo := lrucache.NewCharacterRepository(next, newLogger(), lrucache.Options{Registerer: registry})
+
+	goku := &domain.Character{ID: "1", Name: "Goku", Ki: domain.NewKi("60.000.000"), Race: "Saiyan",
+		Transformations: []domain.Transformation{{ID: "1", Name: "Goku SSJ"}}}
+	next.On("FindCharacterByID", "1").Return(goku, nil).Once()
+
+	first, err := repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, goku, first)
+
+	// Served from memory, under both indexes
+	second, err := repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", second.Name)
+	byName, err := repo.FindCharacterByName(context.Background(), " GOKU ")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", byName.ID)
+	next.AssertExpectations(t)
+
+	// Callers get copies, changing one leaves the cache untouched
+	second.Ki = domain.NewKi("changed")
+	second.Cache = &domain.CacheInfo{Source: domain.CacheSourceLocal}
+	second.Transformations[0].Name = "changed"
+	third, err := repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "60.000.000", third.Ki.String())
+	assert.Equal(t, "Goku SSJ", third.Transformations[0].Name)
+	assert.Nil(t, third.Cache)
+
+	expected := `
+# HELP characters_api_repository_cache_lookups_total In-memory character cache lookups by index (id, name) and result (hit, miss).
+# TYPE characters_api_repository_cache_lookups_total counter
+characters_api_repository_cache_lookups_total{lookup="id",result="hit"} 2
+characters_api_repository_cache_lookups_total{lookup="id",result="miss"} 1
+characters_api_repository_cache_lookups_total{lookup="name",result="hit"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected), "characters_api_repository_cache_lookups_total"))
+}
+
+func TestCharacterRepositoryWritesThrough(t *testing.T) {
+	next := new(MockCharacterRepository)
+	repo := lrucache.NewCharacterRepository(next, newLogger(), lrucache.Options{})
+
+	vegeta := &domain.Character{ID: "2", Name: "Vegeta", Ki: domain.NewKi("54 Trillion"), Race: "Saiyan"}
+	next.On("SaveCharacter", vegeta).Return(nil).Once()
+	assert.NoError(t, repo.SaveCharacter(context.Background(), vegeta))
+
+	// Read back without touching the wrapped repository
+	character, err := repo.FindCharacterByName(context.Background(), "vegeta")
+	assert.NoError(t, err)
+	assert.Equal(t, "54 Trillion", character.Ki.String())
+
+	// A failed save drops the entry, the stored state is unknown
+	next.On("SaveCharacter", mock.Anything).Return(errors.New("connection reset")).Once()
+	assert.Error(t, repo.SaveCharacter(context.Background(), &domain.Character{ID: "2", Name: "Vegeta", Ki: domain.NewKi("unknown")}))
+	next.On("FindCharacterByID", "2").Return(vegeta, nil).Once()
+	character, err = repo.FindCharacterByID(context.Background(), "2")
+	assert.NoError(t, err)
+	assert.Equal(t, "54 Trillion", character.Ki.String())
+	next.AssertExpectations(t)
+}
+
+func TestCharacterRepositoryEvictsLeastRecentlyUsed(t *testing.T) {
+	next := new(MockCharacterRepository)
+	registry := prometheus.NewRegistry()
+	repo := lrucache.NewCharacterRepository(next, newLogger(), lrucache.Options{MaxEntries: 2, Registerer: registry})
+
+	for _, id := range []string{"1", "2"} {
+		next.On("SaveCharacter", mock.Anything).Return(nil).Once()
+		assert.NoError(t, repo.SaveCharacter(context.Background(), &domain.Character{ID: id, Name: "Character " + id}))
+	}
+	// Touch 1 so that 2 is the least recently used when 3 comes in
+	_, err := repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	next.On("SaveCharacter", mock.Anything).Return(nil).Once()
+	assert.NoError(t, repo.SaveCharacter(context.Background(), &domain.Character{ID: "3", Name: "Character 3"}))
+
+	next.On("FindCharacterByID", "2").Return(nil, nil).Once()
+	character, err := repo.FindCharacterByID(context.Background(), "2")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+	next.On("FindCharacterByName", "Character 2").Return(nil, nil).Once()
+	character, err = repo.FindCharacterByName(context.Background(), "Character 2")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+
+	for _, id := range []string{"1", "3"} {
+		character, err := repo.FindCharacterByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, character.ID)
+	}
+	next.AssertExpectations(t)
+
+	expected := `
+# HELP characters_api_repository_cache_entries Characters currently held by the in-memory cache.
+# TYPE characters_api_repository_cache_entries gauge
+characters_api_repository_cache_entries 2
+# HELP characters_api_repository_cache_evictions_total In-memory character cache evictions by reason (capacity, expired, invalidated).
+# TYPE characters_api_repository_cache_evictions_total counter
+characters_api_repository_cache_evictions_total{reason="capacity"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"characters_api_repository_cache_entries", "characters_api_repository_cache_evictions_total"))
+}
+
+func TestCharacterRepositoryEvictsBySize(t *testing.T) {
+	next := new(MockCharacterRepository)
+	registry := prometheus.NewRegistry()
+	// Room for two of the characters below, far from the entry bound
+	repo := lrucache.NewCharacterRepository(next, newLogger(), lrucache.Options{MaxEntries: 100, MaxBytes: 5000, Registerer: registry})
+
+	description := strings.Repeat("x", 1500)
+	for _, id := range []string{"1", "2", "3"} {
+		next.On("SaveCharacter", mock.Anything).Return(nil).Once()
+		assert.NoError(t, repo.SaveCharacter(context.Background(), &domain.Character{ID: id, Name: "Character " + id, Description: description}))
+	}
+
+	next.On("FindCharacterByID", "1").Return(nil, nil).Once()
+	character, err := repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+	for _, id := range []string{"2", "3"} {
+		character, err := repo.FindCharacterByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, character.ID)
+	}
+
+	// A character larger than the whole budget is not cached, and evicts nothing
+	next.On("SaveCharacter", mock.Anything).Return(nil).Once()
+	assert.NoError(t, repo.SaveCharacter(context.Background(), &domain.Character{ID: "4", Name: "Character 4", Description: strings.Repeat("x", 6000)}))
+	next.On("FindCharacterByID", "4").Return(nil, nil).Once()
+	character, err = repo.FindCharacterByID(context.Background(), "4")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+	for _, id := range []string{"2", "3"} {
+		character, err := repo.FindCharacterByID(context.Background(), id)
+		assert.NoError(t, err)
+		assert.Equal(t, id, character.ID)
+	}
+	next.AssertExpectations(t)
+
+	expected := `
+# HELP characters_api_repository_cache_entries Characters currently held by the in-memory cache.
+# TYPE characters_api_repository_cache_entries gauge
+characters_api_repository_cache_entries 2
+# HELP characters_api_repository_cache_evictions_total In-memory character cache evictions by reason (capacity, expired, invalidated).
+# TYPE characters_api_repository_cache_evictions_total counter
+characters_api_repository_cache_evictions_total{reason="capacity"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"characters_api_repository_cache_entries", "characters_api_repository_cache_evictions_total"))
+}
+
+func TestCharacterRepositoryExpiresEntries(t *testing.T) {
+	next := new(MockCharacterRepository)
+	repo := lrucache.NewCharacterRepository(next, newLogger(), lrucache.Options{TTL: 20 * time.Millisecond})
+
+	goku := &domain.Character{ID: "1", Name: "Goku"}
+	next.On("FindCharacterByID", "1").Return(goku, nil).Twice()
+
+	_, err := repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	_, err = repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	next.AssertNumberOfCalls(t, "FindCharacterByID", 1)
+
+	time.Sleep(30 * time.Millisecond)
+	_, err = repo.FindCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	next.AssertNumberOfCalls(t, "FindCharacterByID", 2)
+}
+
+func TestCharacterRepositoryConcurrentAccess(t *testing.T) {
+	next := new(MockCharacterRepository)
+	repo := lrucache.NewCharacterRepository(next, newLogger(), lrucache.Options{MaxEntries: 8})
+
+	next.On("SaveCharacter", mock.Anything).Return(nil)
+	next.On("FindCharacterByID", mock.Anything).Return(nil, nil)
+	next.On("FindCharacterByName", mock.Anything).Return(nil, nil)
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 16; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				id := fmt.Sprint((worker + i) % 12)
+				_ = repo.SaveCharacter(context.Background(), &domain.Character{ID: id, Name: "Character " + id})
+				_, _ = repo.FindCharacterByID(context.Background(), id)
+				_, _ = repo.FindCharacterByName(context.Background(), "character "+id)
+			}
+		}(worker)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,105 @@
+package rediscache_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"backend.go.characters.api/internal/adapters/secondary/rediscache"
+	"backend.go.characters.api/internal/core/domain"
+	"backend.go.characters.api/internal/core/ports"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCache(t *testing.T, opts rediscache.Options) (*miniredis.Miniredis, *redis.Client, ports.CharacterCache) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	return server, client, rediscache.NewCharacterCache(client, logger, opts)
+}
+
+func TestCharacterCacheStoresByIDAndName(t *testing.T) {
+	server, _, cache := newTestCache(t, rediscache.Options{KeyPrefix: "test"})
+
+	updatedAt := time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)
+	goku := &domain.Character{
+		ID:        "1",
+		Name:      "Goku",
+		Ki:        domain.NewKi("60.000.000"),
+		Race:      "Saiyan",
+		UpdatedAt: updatedAt,
+		Cache:     &domain.CacheInfo{Source: domain.CacheSourceUpstream},
+	}
+	assert.NoError(t, cache.SetCharacter(context.Background(), goku))
+	assert.True(t, server.Exists("test:character:id:1"))
+	assert.True(t, server.Exists("test:character:name:goku"))
+
+	byID, err := cache.GetCharacterByID(context.Background(), "1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Goku", byID.Name)
+	assert.True(t, updatedAt.Equal(byID.UpdatedAt))
+	// Serving metadata is not cached
+	assert.Nil(t, byID.Cache)
+
+	byName, err := cache.GetCharacterByName(context.Background(), " GOKU ")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", byName.ID)
+
+	// Unknown characters are a miss, not an error
+	missing, err := cache.GetCharacterByID(context.Background(), "999")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestCharacterCacheExpiresEntries(t *testing.T) {
+	server, _, cache := newTestCache(t, rediscache.Options{TTL: time.Minute})
+
+	assert.NoError(t, cache.SetCharacter(context.Background(), &domain.Character{ID: "1", Name: "Goku"}))
+	assert.Equal(t, time.Minute, server.TTL("characters-api:character:id:1"))
+	assert.Equal(t, time.Minute, server.TTL("characters-api:character:name:goku"))
+
+	server.FastForward(time.Minute)
+	character, err := cache.GetCharacterByName(context.Background(), "Goku")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+}
+
+func TestCharacterCacheInvalidatesCharacters(t *testing.T) {
+	server, _, cache := newTestCache(t, rediscache.Options{})
+
+	assert.NoError(t, cache.SetCharacter(context.Background(), &domain.Character{ID: "1", Name: "Goku"}))
+	assert.NoError(t, cache.SetCharacter(context.Background(), &domain.Character{ID: "2", Name: "Vegeta"}))
+
+	// Unknown IDs are skipped
+	assert.NoError(t, cache.InvalidateCharacters(context.Background(), []string{"1", "999"}))
+	assert.False(t, server.Exists("characters-api:character:id:1"))
+	assert.False(t, server.Exists("characters-api:character:name:goku"))
+	assert.True(t, server.Exists("characters-api:character:id:2"))
+	assert.True(t, server.Exists("characters-api:character:name:vegeta"))
+
+	character, err := cache.GetCharacterByName(context.Background(), "Goku")
+	assert.NoError(t, err)
+	assert.Nil(t, character)
+}
+
+func TestCharacterCacheReportsUnavailableRedis(t *testing.T) {
+	server, client, cache := newTestCache(t, rediscache.Options{})
+	checker := rediscache.NewRedisHealthChecker(client)
+	assert.Equal(t, "redis", checker.Name())
+	assert.NoError(t, checker.Check(context.Background()))
+
+	server.Close()
+
+	_, err := cache.GetCharacterByID(context.Background(), "1")
+	assert.Error(t, err)
+	assert.Error(t, cache.SetCharacter(context.Background(), &domain.Character{ID: "1", Name: "Goku"}))
+	assert.Error(t, cache.InvalidateCharacters(context.Background(), []string{"1"}))
+	assert.Error(t, checker.Check(context.Background()))
+}